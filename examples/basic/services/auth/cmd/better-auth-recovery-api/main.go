@@ -0,0 +1,123 @@
+// better-auth-recovery-api serves a small read-only HTTP API over a store.Backend's
+// RecoveryHashStore: an identity's current recovery hash as a signed receipt a client can
+// verify offline against this server's recovery public key, and a chain-integrity check.
+//
+// Only backends that keep a full verifiable history (pkg/store/verifiablesql today) implement
+// the underlying Current/VerifyChain methods — see readableRecoveryHashStore's doc comment —
+// so every handler here responds 501 against any other backend rather than pretending to serve
+// data it doesn't have.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/jasoncolburne/better-auth-go/examples/crypto"
+
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/store"
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/store/dial"
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/store/verifiablesql"
+)
+
+// readableRecoveryHashStore is the superset of store.RecoveryHashStore that
+// verifiablesql.RecoveryHashStore additionally implements. It's declared here, rather than
+// added to store.RecoveryHashStore itself, because backends without a verifiable chain to
+// replay (memory, badger) have nothing to implement it with — see that type's own doc comment
+// on why Current/VerifyChain aren't part of the shared interface.
+type readableRecoveryHashStore interface {
+	Current(ctx context.Context, identity string) (*verifiablesql.RecoveryHashReceipt, error)
+	VerifyChain(ctx context.Context, identity string) error
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type recoveryAPI struct {
+	readable readableRecoveryHashStore // nil if the configured backend doesn't implement it
+}
+
+func (a *recoveryAPI) handleCurrent(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if a.readable == nil {
+		w.WriteHeader(http.StatusNotImplemented)
+		json.NewEncoder(w).Encode(errorResponse{Error: "backing store has no verifiable recovery-hash chain to read"})
+		return
+	}
+
+	identity := r.URL.Query().Get("identity")
+	if identity == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(errorResponse{Error: "identity is required"})
+		return
+	}
+
+	receipt, err := a.readable.Current(r.Context(), identity)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(errorResponse{Error: err.Error()})
+		return
+	}
+
+	json.NewEncoder(w).Encode(receipt)
+}
+
+func (a *recoveryAPI) handleVerifyChain(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if a.readable == nil {
+		w.WriteHeader(http.StatusNotImplemented)
+		json.NewEncoder(w).Encode(errorResponse{Error: "backing store has no verifiable recovery-hash chain to read"})
+		return
+	}
+
+	identity := r.URL.Query().Get("identity")
+	if identity == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(errorResponse{Error: "identity is required"})
+		return
+	}
+
+	if err := a.readable.VerifyChain(r.Context(), identity); err != nil {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(errorResponse{Error: err.Error()})
+		return
+	}
+
+	json.NewEncoder(w).Encode(struct {
+		Valid bool `json:"valid"`
+	}{Valid: true})
+}
+
+func main() {
+	dsn := flag.String("dsn", "memory://", "store DSN to open (e.g. postgres://...)")
+	addr := flag.String("addr", ":11211", "address to listen on")
+	flag.Parse()
+
+	signer, err := crypto.NewSecp256r1()
+	if err != nil {
+		log.Fatalf("failed to generate recovery receipt signing key: %v", err)
+	}
+
+	stores, err := dial.Open(context.Background(), *dsn, store.Options{RecoverySigner: signer})
+	if err != nil {
+		log.Fatalf("failed to open store: %v", err)
+	}
+
+	api := &recoveryAPI{}
+	if readable, ok := stores.RecoveryHash.(readableRecoveryHashStore); ok {
+		api.readable = readable
+	}
+
+	http.HandleFunc("/recovery-hash/current", api.handleCurrent)
+	http.HandleFunc("/recovery-hash/verify-chain", api.handleVerifyChain)
+
+	log.Printf("better-auth-recovery-api listening on %s (dsn=%s)", *addr, *dsn)
+	if err := http.ListenAndServe(*addr, nil); err != nil {
+		log.Fatalf("server failed: %v", err)
+	}
+}
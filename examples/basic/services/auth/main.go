@@ -2,11 +2,13 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/jasoncolburne/better-auth-go/api"
@@ -15,6 +17,9 @@ import (
 	"github.com/jasoncolburne/better-auth-go/examples/storage"
 	"github.com/jasoncolburne/better-auth-go/pkg/cryptointerfaces"
 	"github.com/redis/go-redis/v9"
+
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/store"
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/store/memory"
 )
 
 type TokenAttributes struct {
@@ -26,6 +31,12 @@ type Server struct {
 	av                *api.AccessVerifier[TokenAttributes]
 	serverAccessKey   cryptointerfaces.SigningKey
 	serverResponseKey cryptointerfaces.SigningKey
+	requestTimeout    time.Duration
+
+	// revocations backs /admin/revoke and /token/is-revoked below. See the BLOCKING NOTE on
+	// adminRevokeToken: this store is real, but nothing in this process actually rejects a
+	// revoked token on the verification path.
+	revocations store.TokenRevocationStore
 }
 
 func NewServer() (*Server, error) {
@@ -112,21 +123,64 @@ func NewServer() (*Server, error) {
 		},
 	)
 
+	requestTimeout, err := requestTimeoutFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	revocations := memory.NewTokenRevocationStore(time.Minute)
+
 	return &Server{
 		ba:                ba,
 		av:                av,
 		serverAccessKey:   serverAccessKey,
 		serverResponseKey: serverResponseKey,
+		requestTimeout:    requestTimeout,
+		revocations:       revocations,
 	}, nil
 }
 
-func wrapResponse(w http.ResponseWriter, r *http.Request, logic func(message string) (string, error)) {
+// requestTimeoutFromEnv reads how long a request's context stays valid once wrapResponse
+// derives it from r.Context(), following the same env-configured-duration convention used
+// elsewhere in this codebase (e.g. the garden-k8s example's analogous RequestTimeout).
+func requestTimeoutFromEnv() (time.Duration, error) {
+	raw := os.Getenv("REQUEST_TIMEOUT_SECONDS")
+	if raw == "" {
+		return 5 * time.Second, nil
+	}
+
+	seconds, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid REQUEST_TIMEOUT_SECONDS: %w", err)
+	}
+
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// unpropagated adapts a BetterAuthServer method to wrapResponse's ctx-first logic signature.
+//
+// NOTE: this example's pinned api.BetterAuthServer (github.com/jasoncolburne/better-auth-go,
+// not vendored in this tree) predates context propagation — its methods take only a message
+// string. wrapResponse still derives a context bounded by RequestTimeout from r.Context() so a
+// client disconnect is observable locally, but that context can't be threaded any deeper than
+// this adapter until BetterAuthServer grows a ctx-first signature for every method (the
+// garden-k8s example's api.BetterAuthServer already has one — see its wrapResponse).
+func unpropagated(logic func(message string) (string, error)) func(ctx context.Context, message string) (string, error) {
+	return func(_ context.Context, message string) (string, error) {
+		return logic(message)
+	}
+}
+
+func (s *Server) wrapResponse(w http.ResponseWriter, r *http.Request, logic func(ctx context.Context, message string) (string, error)) {
 	var reply string
 
 	message, err := io.ReadAll(r.Body)
 
+	ctx, cancel := context.WithTimeout(r.Context(), s.requestTimeout)
+	defer cancel()
+
 	if err == nil {
-		reply, err = logic(string(message))
+		reply, err = logic(ctx, string(message))
 	}
 
 	if err != nil {
@@ -141,31 +195,31 @@ func wrapResponse(w http.ResponseWriter, r *http.Request, logic func(message str
 }
 
 func (s *Server) create(w http.ResponseWriter, r *http.Request) {
-	wrapResponse(w, r, s.ba.CreateAccount)
+	s.wrapResponse(w, r, unpropagated(s.ba.CreateAccount))
 }
 
 func (s *Server) recover(w http.ResponseWriter, r *http.Request) {
-	wrapResponse(w, r, s.ba.RecoverAccount)
+	s.wrapResponse(w, r, unpropagated(s.ba.RecoverAccount))
 }
 
 func (s *Server) delete(w http.ResponseWriter, r *http.Request) {
-	wrapResponse(w, r, s.ba.DeleteAccount)
+	s.wrapResponse(w, r, unpropagated(s.ba.DeleteAccount))
 }
 
 func (s *Server) link(w http.ResponseWriter, r *http.Request) {
-	wrapResponse(w, r, s.ba.LinkDevice)
+	s.wrapResponse(w, r, unpropagated(s.ba.LinkDevice))
 }
 
 func (s *Server) unlink(w http.ResponseWriter, r *http.Request) {
-	wrapResponse(w, r, s.ba.UnlinkDevice)
+	s.wrapResponse(w, r, unpropagated(s.ba.UnlinkDevice))
 }
 
 func (s *Server) startAuthentication(w http.ResponseWriter, r *http.Request) {
-	wrapResponse(w, r, s.ba.RequestSession)
+	s.wrapResponse(w, r, unpropagated(s.ba.RequestSession))
 }
 
 func (s *Server) finishAuthentication(w http.ResponseWriter, r *http.Request) {
-	wrapResponse(w, r, func(message string) (string, error) {
+	s.wrapResponse(w, r, func(ctx context.Context, message string) (string, error) {
 		return s.ba.CreateSession(message, TokenAttributes{
 			PermissionsByRole: map[string][]string{
 				"user": {
@@ -178,11 +232,91 @@ func (s *Server) finishAuthentication(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) rotateAuthentication(w http.ResponseWriter, r *http.Request) {
-	wrapResponse(w, r, s.ba.RotateDevice)
+	s.wrapResponse(w, r, unpropagated(s.ba.RotateDevice))
 }
 
 func (s *Server) rotateAccess(w http.ResponseWriter, r *http.Request) {
-	wrapResponse(w, r, s.ba.RefreshSession)
+	s.wrapResponse(w, r, unpropagated(s.ba.RefreshSession))
+}
+
+// adminRevokeRequest is the body /admin/revoke expects: the hash of the access token to deny
+// (not the token itself, the way AccessKeyHashStore.Reserve is also keyed by hash rather than
+// by key material) and when the denylist entry may be dropped.
+type adminRevokeRequest struct {
+	TokenHash string    `json:"tokenHash"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// adminRevokeToken lets an operator denylist an already-issued access token before it would
+// otherwise expire.
+//
+// BLOCKING CROSS-CUTTING GAP: this only writes to s.revocations. Nothing in this process reads
+// it back on the verification path — av's token verification (signature + access nonce) lives
+// entirely inside api.AccessVerifier, in the unvendored github.com/jasoncolburne/better-auth-go
+// dependency, and this tree has no way to add a revocation check inside it. A 200 "revoked"
+// response here does NOT make the token stop working anywhere av.Verify (or an equivalent in a
+// downstream resource server) is the thing actually checking it - it only starts answering true
+// from /token/is-revoked. Closing this gap for real needs either an upstream change to
+// AccessVerifier (out of scope for this repo) or every verifier call site gaining an explicit
+// IsRevoked check of its own, using /token/is-revoked (see handleIsRevoked) as a second opinion
+// after its own signature/nonce check succeeds - the same pattern this file would use locally
+// if av.Verify were ever called here, which today it is not.
+func (s *Server) adminRevokeToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req adminRevokeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, "{\"error\":\"invalid request body\"}")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.requestTimeout)
+	defer cancel()
+
+	if err := s.revocations.Revoke(ctx, req.TokenHash, req.ExpiresAt); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "{\"error\":\"an error occurred\"}")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, "{\"status\":\"revoked\"}")
+}
+
+// handleIsRevoked is the read side adminRevokeToken's write needs to be worth anything: a
+// downstream resource server that already verifies a token's signature and access nonce itself
+// (its own equivalent of av.Verify) can call this with the same tokenHash immediately
+// afterward, and reject the token if it comes back revoked. See adminRevokeToken's BLOCKING
+// CROSS-CUTTING GAP note for why this process can't perform that combined check itself.
+func (s *Server) handleIsRevoked(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	tokenHash := r.URL.Query().Get("tokenHash")
+	if tokenHash == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, "{\"error\":\"tokenHash is required\"}")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.requestTimeout)
+	defer cancel()
+
+	revoked, err := s.revocations.IsRevoked(ctx, tokenHash)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "{\"error\":\"an error occurred\"}")
+		return
+	}
+
+	json.NewEncoder(w).Encode(struct {
+		Revoked bool `json:"revoked"`
+	}{Revoked: revoked})
 }
 
 func (s *Server) healthCheck(w http.ResponseWriter, r *http.Request) {
@@ -213,6 +347,9 @@ func (s *Server) StartServer() error {
 	http.HandleFunc("/device/link", s.link)
 	http.HandleFunc("/device/unlink", s.unlink)
 
+	http.HandleFunc("/admin/revoke", s.adminRevokeToken)
+	http.HandleFunc("/token/is-revoked", s.handleIsRevoked)
+
 	// Handle OPTIONS for CORS
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == "OPTIONS" {
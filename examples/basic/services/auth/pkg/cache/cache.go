@@ -0,0 +1,29 @@
+// Package cache declares a narrow key/value interface for the fast-lookup layer a store can
+// compose in front of its authoritative repository, independent of whether that layer is an
+// in-process LRU (pkg/cache/memory) or Redis (pkg/cache/rediscache).
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache is deliberately narrow: Get/Set/Delete cover single-key reads and writes, and
+// Invalidate covers bulk eviction by prefix (e.g. every device cached under one identity)
+// without requiring callers to enumerate keys themselves.
+type Cache interface {
+	// Get reports whether key is cached and, if so, its value. A false ok with a nil err
+	// means a clean miss; callers should fall through to the authoritative store.
+	Get(ctx context.Context, key string) (value string, ok bool, err error)
+
+	// Set caches value for key until ttl elapses. A zero ttl means "no expiry", which callers
+	// should use sparingly since it defeats the point of treating this as a cache.
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+
+	// Delete evicts key, if present. Deleting an absent key is not an error.
+	Delete(ctx context.Context, key string) error
+
+	// Invalidate evicts every key beginning with prefix, for bulk invalidation such as
+	// revoking every device cached under an identity.
+	Invalidate(ctx context.Context, prefix string) error
+}
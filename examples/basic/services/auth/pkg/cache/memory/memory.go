@@ -0,0 +1,118 @@
+// Package memory provides a process-local, LRU-bounded cache.Cache, for deployments and
+// tests that don't want a Redis dependency in front of their store.
+package memory
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+type entry struct {
+	value     string
+	expiresAt time.Time // zero means "no expiry"
+	element   *list.Element
+}
+
+// Cache is an in-process, mutex-guarded cache.Cache backed by a bounded LRU list. It does not
+// shard the way CachedKeyHashReservationStore does, since Invalidate needs to walk every entry
+// for a prefix match anyway and a single lock keeps that straightforward.
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+	lru     *list.List
+	maxSize int // 0 means unbounded
+}
+
+// NewCache builds an empty Cache that holds at most maxSize entries, evicting the least
+// recently touched entry first once full. maxSize <= 0 means unbounded.
+func NewCache(maxSize int) *Cache {
+	return &Cache{
+		entries: map[string]*entry{},
+		lru:     list.New(),
+		maxSize: maxSize,
+	}
+}
+
+func (c *Cache) Get(ctx context.Context, key string) (string, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	found, ok := c.entries[key]
+	if !ok {
+		return "", false, nil
+	}
+
+	if !found.expiresAt.IsZero() && !time.Now().Before(found.expiresAt) {
+		c.evictLocked(key)
+		return "", false, nil
+	}
+
+	c.lru.MoveToFront(found.element)
+
+	return found.value, true, nil
+}
+
+func (c *Cache) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	if found, ok := c.entries[key]; ok {
+		found.value = value
+		found.expiresAt = expiresAt
+		c.lru.MoveToFront(found.element)
+		return nil
+	}
+
+	if c.maxSize > 0 && len(c.entries) >= c.maxSize {
+		oldest := c.lru.Back()
+		if oldest != nil {
+			c.evictLocked(oldest.Value.(string))
+		}
+	}
+
+	element := c.lru.PushFront(key)
+	c.entries[key] = &entry{value: value, expiresAt: expiresAt, element: element}
+
+	return nil
+}
+
+func (c *Cache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.evictLocked(key)
+
+	return nil
+}
+
+func (c *Cache) Invalidate(ctx context.Context, prefix string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			c.evictLocked(key)
+		}
+	}
+
+	return nil
+}
+
+// evictLocked removes key, if present. Caller must hold mu.
+func (c *Cache) evictLocked(key string) {
+	found, ok := c.entries[key]
+	if !ok {
+		return
+	}
+
+	c.lru.Remove(found.element)
+	delete(c.entries, key)
+}
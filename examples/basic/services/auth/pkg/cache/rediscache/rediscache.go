@@ -0,0 +1,113 @@
+// Package rediscache provides a Redis-backed cache.Cache, for deployments that want the
+// cache layer shared across multiple instances of this service rather than process-local.
+package rediscache
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Cache is a cache.Cache backed by a redis.UniversalClient, so it works against any of the
+// topologies implementation.RedisConfig.NewClient builds (standalone, sentinel, cluster).
+type Cache struct {
+	client redis.UniversalClient
+}
+
+func NewCache(client redis.UniversalClient) *Cache {
+	return &Cache{client: client}
+}
+
+func (c *Cache) Get(ctx context.Context, key string) (string, bool, error) {
+	value, err := retryRedisOperation(ctx, func() (string, error) {
+		return c.client.Get(ctx, key).Result()
+	})
+	if err == redis.Nil {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	return value, true, nil
+}
+
+func (c *Cache) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	_, err := retryRedisOperation(ctx, func() (struct{}, error) {
+		return struct{}{}, c.client.Set(ctx, key, value, ttl).Err()
+	})
+
+	return err
+}
+
+func (c *Cache) Delete(ctx context.Context, key string) error {
+	_, err := retryRedisOperation(ctx, func() (struct{}, error) {
+		return struct{}{}, c.client.Del(ctx, key).Err()
+	})
+
+	return err
+}
+
+// scanBatch is one page of Invalidate's SCAN: the keys it turned up, and the cursor to resume
+// from (0 once the scan has wrapped back to the start).
+type scanBatch struct {
+	keys   []string
+	cursor uint64
+}
+
+// Invalidate SCANs for every key beginning with prefix and deletes them in batches. SCAN
+// rather than KEYS so this doesn't block the Redis server while it runs.
+func (c *Cache) Invalidate(ctx context.Context, prefix string) error {
+	var cursor uint64
+
+	for {
+		batch, err := retryRedisOperation(ctx, func() (scanBatch, error) {
+			keys, next, err := c.client.Scan(ctx, cursor, prefix+"*", 0).Result()
+			return scanBatch{keys: keys, cursor: next}, err
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(batch.keys) > 0 {
+			if _, err := retryRedisOperation(ctx, func() (struct{}, error) {
+				return struct{}{}, c.client.Del(ctx, batch.keys...).Err()
+			}); err != nil {
+				return err
+			}
+		}
+
+		cursor = batch.cursor
+		if cursor == 0 {
+			break
+		}
+	}
+
+	return nil
+}
+
+// retryRedisOperation executes a Redis operation with retry logic and exponential backoff,
+// mirroring pkg/implementation/redisadapter's helper of the same name.
+func retryRedisOperation[T any](ctx context.Context, operation func() (T, error)) (T, error) {
+	const maxRetries = 3
+	const initialBackoff = 100 * time.Millisecond
+
+	var lastErr error
+	var zero T
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(initialBackoff * time.Duration(1<<uint(attempt-1)))
+		}
+
+		result, err := operation()
+		if err == nil || err == redis.Nil {
+			return result, err
+		}
+
+		lastErr = err
+	}
+
+	return zero, lastErr
+}
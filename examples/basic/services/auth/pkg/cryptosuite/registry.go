@@ -0,0 +1,99 @@
+// Package cryptosuite lets this service accept device keys signed under more than one
+// algorithm. github.com/jasoncolburne/better-auth-go/pkg/cryptointerfaces declares the
+// Verifier/Hasher contracts themselves but isn't ours to extend (it's vendored from that
+// dependency), so the registry mapping a key's algorithm tag to the {Verifier, Hasher} pair
+// that understands it lives here instead.
+//
+// A public key or rotation-hash commitment carries its suite as a short CESR-style prefix on
+// the serialized string (e.g. "1AAD" for Ed25519), the same convention the garden-k8s HSM
+// service's cesr.go already uses for its own key material. A value with none of the
+// registered prefixes is treated as the legacy, untagged secp256r1+Blake3 suite this service
+// shipped with before suite tagging existed, so existing persisted public_key and
+// rotation_hash columns keep verifying unchanged.
+package cryptosuite
+
+import (
+	"strings"
+
+	"github.com/jasoncolburne/better-auth-go/examples/crypto"
+	"github.com/jasoncolburne/better-auth-go/pkg/cryptointerfaces"
+)
+
+// Suite pairs the Verifier that checks a device key's signatures with the Hasher that checks
+// its rotation-hash commitments, under one algorithm tag. Tag is "" for the legacy suite,
+// which never prefixes the values it produces or expects.
+type Suite struct {
+	Tag      string
+	Verifier cryptointerfaces.Verifier
+	Hasher   cryptointerfaces.Hasher
+}
+
+// TagValue prefixes value with this suite's tag, the form Rotate/Verify callers should store
+// or compare against. The legacy suite's Tag is "", so this is a no-op for it.
+func (s Suite) TagValue(value string) string {
+	return s.Tag + value
+}
+
+// Registry dispatches a tagged public key or rotation-hash string to the Suite that
+// understands it, falling back to legacy for anything untagged.
+type Registry struct {
+	legacy Suite
+	suites map[string]Suite
+}
+
+// NewRegistry builds a Registry whose fallback, for any value matching none of its registered
+// suites' tags, is legacy.
+func NewRegistry(legacy Suite) *Registry {
+	return &Registry{
+		legacy: legacy,
+		suites: map[string]Suite{},
+	}
+}
+
+// Register adds suite to the registry, keyed by its own Tag. A later Register call for the
+// same tag replaces the previous one.
+func (r *Registry) Register(suite Suite) {
+	r.suites[suite.Tag] = suite
+}
+
+// Lookup returns the Suite whose tag prefixes tagged, or the registry's legacy suite if none
+// matches.
+func (r *Registry) Lookup(tagged string) Suite {
+	for tag, suite := range r.suites {
+		if tag != "" && strings.HasPrefix(tagged, tag) {
+			return suite
+		}
+	}
+
+	return r.legacy
+}
+
+// legacySuite is secp256r1+Blake3, this service's original, untagged default. Its Verifier
+// and Hasher already accept the CESR-tagged "1AAI" public keys the HSM service emits as well
+// as bare legacy ones, so it doubles as the suite behind the explicitly-registered "1AAI" tag
+// below.
+func legacySuite() Suite {
+	return Suite{
+		Tag:      "",
+		Verifier: crypto.NewSecp256r1Verifier(),
+		Hasher:   crypto.NewBlake3(),
+	}
+}
+
+// Default is the process-wide Registry every NewVerificationKey call and
+// AuthenticationKeyStore rotation-hash check consults. A deployment that wants to accept an
+// additional algorithm should call Default.Register at startup, before any request reaches
+// those code paths.
+var Default = newDefaultRegistry()
+
+func newDefaultRegistry() *Registry {
+	legacy := legacySuite()
+
+	registry := NewRegistry(legacy)
+
+	registry.Register(Suite{Tag: "1AAI", Verifier: legacy.Verifier, Hasher: legacy.Hasher})
+	registry.Register(Suite{Tag: "1AAD", Verifier: newEd25519Verifier(), Hasher: crypto.NewBlake3()})
+	registry.Register(Suite{Tag: "1AAB", Verifier: newSecp256k1Verifier(), Hasher: newSha256Hasher()})
+
+	return registry
+}
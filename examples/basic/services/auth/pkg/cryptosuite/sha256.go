@@ -0,0 +1,26 @@
+package cryptosuite
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// sha256Sum hashes message with SHA-256, the digest secp256k1Verifier signs over - Bitcoin and
+// Ethereum's own convention, unlike the Blake3 digest the rest of this service's suites use.
+func sha256Sum(message []byte) []byte {
+	digest := sha256.Sum256(message)
+	return digest[:]
+}
+
+// sha256Hasher is a cryptointerfaces.Hasher pairing with secp256k1Verifier, for rotation-hash
+// commitments on secp256k1 device keys.
+type sha256Hasher struct{}
+
+func newSha256Hasher() *sha256Hasher {
+	return &sha256Hasher{}
+}
+
+func (h *sha256Hasher) Sum(data []byte) string {
+	digest := sha256.Sum256(data)
+	return base64.URLEncoding.EncodeToString(digest[:])
+}
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data"
+	"github.com/jmoiron/sqlx"
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteStore is a SQLite-backed data.Store, for deployments and local development that want
+// the verifiable-storage-go chain semantics without standing up a Postgres instance.
+type SQLiteStore struct {
+	db *sqlx.DB
+	tx *sqlx.Tx
+}
+
+// NewSQLiteStore opens dsn (e.g. "file:/var/lib/auth.db" or "file::memory:?cache=shared") via
+// modernc.org/sqlite's pure-Go driver and applies migrations.
+func NewSQLiteStore(ctx context.Context, dsn string, migrations []string) (*SQLiteStore, error) {
+	db, err := sqlx.Connect("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, migration := range migrations {
+		if _, err := db.ExecContext(ctx, migration); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	return &SQLiteStore{
+		db: db.Unsafe(), // the unsafe here allows us to gracefully ignore computed columns
+		tx: nil,
+	}, nil
+}
+
+func (s SQLiteStore) Sql() data.SQLStore {
+	if s.tx == nil {
+		return s.db
+	} else {
+		return s.tx
+	}
+}
+
+func (s *SQLiteStore) BeginTransaction(ctx context.Context, opts *sql.TxOptions) error {
+	if s.tx != nil {
+		return fmt.Errorf("transaction in progress")
+	}
+
+	var err error
+	s.tx, err = s.db.BeginTxx(ctx, opts)
+	if err != nil {
+		s.tx = nil
+		return err
+	}
+
+	return nil
+}
+
+func (s *SQLiteStore) CommitTransaction() error {
+	if s.tx == nil {
+		return fmt.Errorf("no transaction in progress")
+	}
+
+	if err := s.tx.Commit(); err != nil {
+		return err
+	}
+
+	s.tx = nil
+
+	return nil
+}
+
+func (s *SQLiteStore) RollbackTransaction() error {
+	if s.tx == nil {
+		return fmt.Errorf("no transaction in progress")
+	}
+
+	if err := s.tx.Rollback(); err != nil {
+		return err
+	}
+
+	s.tx = nil
+
+	return nil
+}
+
+// ReplacePlaceholders is a no-op: SQLite, unlike Postgres, takes "?" placeholders natively.
+func (*SQLiteStore) ReplacePlaceholders(query string) string {
+	return query
+}
@@ -0,0 +1,24 @@
+package did
+
+// VerificationMethod is one key a relying party can use to verify signatures from the
+// identity's controller, derived from a single device's latest non-revoked authentication key.
+type VerificationMethod struct {
+	Id            string `json:"id"`
+	Type          string `json:"type"`
+	Controller    string `json:"controller"`
+	PublicKeyCESR string `json:"publicKeyCesr"`
+}
+
+// DIDDocument is the subset of the W3C DID document shape these resolvers can populate from
+// an identity's authentication keys and KEL.
+type DIDDocument struct {
+	Context            []string             `json:"@context"`
+	Id                 string               `json:"id"`
+	Controller         string               `json:"controller"`
+	VerificationMethod []VerificationMethod `json:"verificationMethod"`
+
+	// NextKeyHashes carries each verification method's rotation-hash commitment, in the
+	// same order as VerificationMethod, so a relying party can confirm a future rotation
+	// without re-resolving the document.
+	NextKeyHashes []string `json:"nextKeyHashes"`
+}
@@ -0,0 +1,69 @@
+package did
+
+import (
+	"context"
+	"fmt"
+)
+
+// DeviceKey is one device's latest non-revoked authentication key, as carried by the
+// AuthenticationKeys store.
+type DeviceKey struct {
+	Device       string
+	PublicKey    string
+	RotationHash string
+}
+
+// AuthenticationKeySource is the data this resolver needs from AuthenticationKeyStore,
+// kept narrow so pkg/did doesn't depend on pkg/implementation.
+type AuthenticationKeySource interface {
+	ActiveDeviceKeys(ctx context.Context, identity string) ([]DeviceKey, error)
+}
+
+// KeriResolver resolves did:keri DIDs by assembling a DID document from an identity's active
+// device keys: controller is the identity prefix, one verification method per device, and
+// each device's rotation-hash commitment carried alongside as a next-key hash.
+type KeriResolver struct {
+	keys AuthenticationKeySource
+}
+
+func NewKeriResolver(keys AuthenticationKeySource) *KeriResolver {
+	return &KeriResolver{keys: keys}
+}
+
+func (r *KeriResolver) Resolve(ctx context.Context, didStr string) (*DIDDocument, error) {
+	method, prefix, err := Parse(didStr)
+	if err != nil {
+		return nil, err
+	}
+
+	if method != "keri" {
+		return nil, fmt.Errorf("keri resolver cannot resolve did method %q", method)
+	}
+
+	deviceKeys, err := r.keys.ActiveDeviceKeys(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(deviceKeys) == 0 {
+		return nil, fmt.Errorf("identity %s has no active authentication keys", prefix)
+	}
+
+	doc := &DIDDocument{
+		Context:    []string{"https://www.w3.org/ns/did/v1"},
+		Id:         didStr,
+		Controller: prefix,
+	}
+
+	for _, deviceKey := range deviceKeys {
+		doc.VerificationMethod = append(doc.VerificationMethod, VerificationMethod{
+			Id:            fmt.Sprintf("%s#%s", didStr, deviceKey.Device),
+			Type:          "CESRVerificationKey2026",
+			Controller:    prefix,
+			PublicKeyCESR: deviceKey.PublicKey,
+		})
+		doc.NextKeyHashes = append(doc.NextKeyHashes, deviceKey.RotationHash)
+	}
+
+	return doc, nil
+}
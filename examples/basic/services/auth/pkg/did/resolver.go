@@ -0,0 +1,56 @@
+package did
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Resolver resolves a DID into a DIDDocument. Each supported did:<method> gets its own
+// Resolver, registered in a Registry so callers don't need to know which method they're
+// dealing with.
+type Resolver interface {
+	Resolve(ctx context.Context, did string) (*DIDDocument, error)
+}
+
+// Registry dispatches Resolve calls to the Resolver registered for a DID's method.
+type Registry struct {
+	resolvers map[string]Resolver
+}
+
+func NewRegistry() *Registry {
+	return &Registry{resolvers: map[string]Resolver{}}
+}
+
+func (r *Registry) Register(method string, resolver Resolver) {
+	r.resolvers[method] = resolver
+}
+
+func (r *Registry) Resolve(ctx context.Context, did string) (*DIDDocument, error) {
+	method, _, err := Parse(did)
+	if err != nil {
+		return nil, err
+	}
+
+	resolver, ok := r.resolvers[method]
+	if !ok {
+		return nil, fmt.Errorf("no resolver registered for did method %q", method)
+	}
+
+	return resolver.Resolve(ctx, did)
+}
+
+// IsDID reports whether s looks like a DID rather than a raw identity prefix.
+func IsDID(s string) bool {
+	return strings.HasPrefix(s, "did:")
+}
+
+// Parse splits a DID of the form "did:<method>:<identifier>" into its method and identifier.
+func Parse(did string) (method, identifier string, err error) {
+	parts := strings.SplitN(did, ":", 3)
+	if len(parts) != 3 || parts[0] != "did" {
+		return "", "", fmt.Errorf("invalid did: %q", did)
+	}
+
+	return parts[1], parts[2], nil
+}
@@ -0,0 +1,40 @@
+package did
+
+import (
+	"context"
+	"fmt"
+)
+
+// WebsResolver resolves did:webs DIDs by delegating to a KeriResolver against the same
+// identity prefix, then additionally knows how to publish the result as a signed JSON file
+// over HTTPS at the conventional did:webs well-known path.
+type WebsResolver struct {
+	*KeriResolver
+	domain string
+}
+
+func NewWebsResolver(keys AuthenticationKeySource, domain string) *WebsResolver {
+	return &WebsResolver{
+		KeriResolver: NewKeriResolver(keys),
+		domain:       domain,
+	}
+}
+
+func (r *WebsResolver) Resolve(ctx context.Context, didStr string) (*DIDDocument, error) {
+	method, prefix, err := Parse(didStr)
+	if err != nil {
+		return nil, err
+	}
+
+	if method != "webs" {
+		return nil, fmt.Errorf("webs resolver cannot resolve did method %q", method)
+	}
+
+	return r.KeriResolver.Resolve(ctx, fmt.Sprintf("did:keri:%s", prefix))
+}
+
+// WellKnownPath returns the conventional location a did:webs document is published at:
+// https://<domain>/.well-known/keri.json
+func (r *WebsResolver) WellKnownPath() string {
+	return fmt.Sprintf("https://%s/.well-known/keri.json", r.domain)
+}
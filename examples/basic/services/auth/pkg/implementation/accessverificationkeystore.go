@@ -1,26 +1,32 @@
 package implementation
 
 import (
+	"container/list"
 	"context"
 	"encoding/json"
 	"fmt"
 	"os"
-	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/jasoncolburne/better-auth-go/examples/crypto"
 	"github.com/jasoncolburne/better-auth-go/examples/encoding"
 	"github.com/jasoncolburne/better-auth-go/pkg/cryptointerfaces"
 	"github.com/jasoncolburne/better-auth-go/pkg/encodinginterfaces"
-	"github.com/jasoncolburne/verifiable-storage-go/pkg/algorithms"
 	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
 	"github.com/redis/go-redis/v9"
+
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/cryptosuite"
 )
 
 const HSM_IDENTITY = "BETTER_AUTH_HSM_IDENTITY_PLACEHOLDER"
 
+// keyVerifierRestartThreshold is added to accessLifetime when deciding how far back a cache
+// entry needs to stay valid: a pod that's been down for up to this long should still find every
+// generation id it might see on restart already cached.
+const keyVerifierRestartThreshold = 12 * time.Hour
+
 type LogEntry struct {
 	primitives.VerifiableRecorder
 	Purpose      string `json:"purpose"`
@@ -33,20 +39,46 @@ type SignedLogEntry struct {
 	Signature string   `json:"signature"`
 }
 
+// prefixChainState is what KeyVerifier remembers about the last record verified for one HSM
+// prefix, so a newly-arrived tail record (from the pub/sub feed) can be verified against just
+// this record instead of replaying the prefix's whole chain from scratch.
+type prefixChainState struct {
+	lastSequenceNumber uint64
+	lastId             string
+	lastRotationHash   string
+}
+
+// cachedLogEntry is one generation id's cached LogEntry, with the LRU list element and
+// expiration backing KeyVerifier's bounded eviction.
+type cachedLogEntry struct {
+	payload   *LogEntry
+	expiresAt time.Time
+	element   *list.Element
+}
+
+// KeyVerifier checks access-key authorizations against a chain of SignedLogEntry records
+// produced by a custom HSM service and written to Redis. Rather than replaying the entire
+// chain on every cache miss, it bootstraps once via SCAN, then keeps itself current by
+// subscribing to the channel the HSM writer publishes new records on, verifying and appending
+// only the new tail as it arrives.
 type KeyVerifier struct {
-	client         *redis.Client
-	verifier       cryptointerfaces.Verifier
-	hasher         cryptointerfaces.Hasher
-	cache          map[string]*LogEntry
+	client         redis.UniversalClient
+	registry       *cryptosuite.Registry
 	accessLifetime time.Duration
+	scanBatchSize  int64
+	channel        string
+
+	mu           sync.RWMutex
+	chains       map[string]*prefixChainState
+	entries      map[string]*cachedLogEntry
+	lru          *list.List // front = most recently inserted/updated
+	maxEntries   int
+	bootstrapped bool
+
+	subCancel context.CancelFunc
 }
 
 func NewKeyVerifier(accessLifetime time.Duration) (*KeyVerifier, error) {
-	redisHost := os.Getenv("REDIS_HOST")
-	if redisHost == "" {
-		redisHost = "redis:6379"
-	}
-
 	redisDbHsmKeysString := os.Getenv("REDIS_DB_HSM_KEYS")
 
 	redisDbHsmKeys, err := strconv.Atoi(redisDbHsmKeysString)
@@ -54,23 +86,76 @@ func NewKeyVerifier(accessLifetime time.Duration) (*KeyVerifier, error) {
 		return nil, err
 	}
 
-	hsmKeysClient := redis.NewClient(&redis.Options{
-		Addr: redisHost,
-		DB:   redisDbHsmKeys,
-	})
+	redisConfig, err := redisConfigFromEnv(redisDbHsmKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	hsmKeysClient, err := redisConfig.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	scanBatchSize, err := hsmKeyScanBatchSizeFromEnv()
+	if err != nil {
+		return nil, err
+	}
 
-	verifier := crypto.NewSecp256r1Verifier()
-	hasher := crypto.NewBlake3()
+	channel := os.Getenv("HSM_KEY_LOG_CHANNEL")
+	if channel == "" {
+		channel = "hsm:keylog"
+	}
+
+	maxEntries, err := hsmKeyCacheMaxEntriesFromEnv()
+	if err != nil {
+		return nil, err
+	}
 
 	return &KeyVerifier{
 		client:         hsmKeysClient,
-		verifier:       verifier,
-		hasher:         hasher,
-		cache:          map[string]*LogEntry{},
+		registry:       cryptosuite.Default,
 		accessLifetime: accessLifetime,
+		scanBatchSize:  scanBatchSize,
+		channel:        channel,
+		chains:         map[string]*prefixChainState{},
+		entries:        map[string]*cachedLogEntry{},
+		lru:            list.New(),
+		maxEntries:     maxEntries,
 	}, nil
 }
 
+// hsmKeyScanBatchSizeFromEnv reads how many keys KeyVerifier's bootstrap SCAN asks Redis for
+// per round trip, following the same env-configured convention as the rest of this codebase.
+func hsmKeyScanBatchSizeFromEnv() (int64, error) {
+	raw := os.Getenv("HSM_KEY_SCAN_BATCH_SIZE")
+	if raw == "" {
+		return 100, nil
+	}
+
+	batchSize, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid HSM_KEY_SCAN_BATCH_SIZE: %w", err)
+	}
+
+	return batchSize, nil
+}
+
+// hsmKeyCacheMaxEntriesFromEnv reads how many generation ids KeyVerifier's LRU cache holds at
+// once; 0 means unbounded (rely on TTL eviction alone).
+func hsmKeyCacheMaxEntriesFromEnv() (int, error) {
+	raw := os.Getenv("HSM_KEY_CACHE_MAX_ENTRIES")
+	if raw == "" {
+		return 10000, nil
+	}
+
+	maxEntries, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid HSM_KEY_CACHE_MAX_ENTRIES: %w", err)
+	}
+
+	return maxEntries, nil
+}
+
 func (v *KeyVerifier) Verify(
 	ctx context.Context,
 	signature,
@@ -78,154 +163,58 @@ func (v *KeyVerifier) Verify(
 	hsmGenerationId string,
 	message []byte,
 ) error {
-	cachedEntry, ok := v.cache[hsmGenerationId]
+	entry, ok := v.lookup(hsmGenerationId)
 	if !ok {
-		recordStrings, err := retryRedisOperation(ctx, func() ([]any, error) {
-			keys, err := v.client.Keys(ctx, "*").Result()
-			if err != nil {
-				return nil, err
-			}
-
-			return v.client.MGet(ctx, keys...).Result()
-		})
-		if err != nil {
+		if err := v.ensureBootstrapped(ctx); err != nil {
 			return err
 		}
 
-		byPrefix := map[string][]*SignedLogEntry{}
-		for _, recordString := range recordStrings {
-			bytes, ok := recordString.(string)
-			if !ok {
-				return fmt.Errorf("unexpected type for record")
-			}
-
-			record := &SignedLogEntry{}
-			if err := json.Unmarshal([]byte(bytes), record); err != nil {
-				return err
-			}
-
-			prefix := record.Payload.Prefix
-			list, ok := byPrefix[prefix]
-			if ok {
-				list = append(list, record)
-				byPrefix[prefix] = list
-			} else {
-				byPrefix[prefix] = []*SignedLogEntry{record}
-			}
-		}
-
-		for prefix, records := range byPrefix {
-			slices.SortFunc(records, func(a *SignedLogEntry, b *SignedLogEntry) int {
-				if a.Payload.SequenceNumber < b.Payload.SequenceNumber {
-					return -1
-				}
-
-				if a.Payload.SequenceNumber > b.Payload.SequenceNumber {
-					return 1
-				}
-
-				return 0
-			})
-
-			byPrefix[prefix] = records
-		}
-
-		// verify data & signatures
-		for _, records := range byPrefix {
-			for _, record := range records {
-				payload := record.Payload
-
-				if payload.SequenceNumber == 0 {
-					if err := algorithms.VerifyPrefixAndData(&payload); err != nil {
-						return err
-					}
-				} else {
-					if err := algorithms.VerifyAddressAndData(&payload); err != nil {
-						return err
-					}
-				}
-
-				message, err := json.Marshal(payload)
-				if err != nil {
-					return err
-				}
-
-				if err := v.verifier.Verify(record.Signature, payload.PublicKey, message); err != nil {
-					return err
-				}
-			}
-		}
-
-		// verify chains
-		for _, records := range byPrefix {
-			lastId := ""
-			lastRotationHash := ""
-			for i, record := range records {
-				payload := record.Payload
-
-				if int(payload.SequenceNumber) != i {
-					return fmt.Errorf("bad sequence number")
-				}
-
-				if payload.SequenceNumber != 0 {
-					if lastId != *payload.Previous {
-						return fmt.Errorf("broken chain")
-					}
-
-					hash := v.hasher.Sum([]byte(payload.PublicKey))
-
-					if !strings.EqualFold(hash, lastRotationHash) {
-						return fmt.Errorf("bad commitment")
-					}
-				}
-
-				lastId = payload.Id
-				lastRotationHash = payload.RotationHash
-			}
-		}
-
-		// verify prefix
-		records, ok := byPrefix[HSM_IDENTITY]
-		if !ok {
-			return fmt.Errorf("hsm identity not found")
-		}
-
-		for i := len(records) - 1; i >= 0; i-- {
-			payload := records[i].Payload
-
-			v.cache[payload.Id] = &payload
-
-			when := (time.Time)(*payload.CreatedAt)
-			// server restart threshold + token lifetime
-			if when.Add(v.accessLifetime + 12*time.Hour).Before(time.Now()) {
-				break
-			}
-		}
-
-		cachedEntry, ok = v.cache[hsmGenerationId]
+		entry, ok = v.lookup(hsmGenerationId)
 		if !ok {
 			return fmt.Errorf("can't find valid public key")
 		}
 	}
 
-	if cachedEntry.Prefix != hsmIdentity {
+	if entry.Prefix != hsmIdentity {
 		return fmt.Errorf("incorrect identity (expected hsm.identity == prefix)")
 	}
 
-	if cachedEntry.Purpose != "key-authorization" {
+	if entry.Purpose != "key-authorization" {
 		return fmt.Errorf("incorrect purpose (expected key-authorization)")
 	}
 
-	publicKey := cachedEntry.PublicKey
-
-	// verify message signature
-	if err := v.verifier.Verify(signature, publicKey, message); err != nil {
+	// verify message signature, dispatching to the suite entry.PublicKey is tagged with
+	suite := v.registry.Lookup(entry.PublicKey)
+	if err := suite.Verifier.Verify(signature, entry.PublicKey, message); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// lookup returns hsmGenerationId's cached LogEntry, if present and not yet expired.
+func (v *KeyVerifier) lookup(hsmGenerationId string) (*LogEntry, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	entry, ok := v.entries[hsmGenerationId]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+
+	return entry.payload, true
+}
+
+// Close releases the underlying Redis client and stops the pub/sub subscription goroutine, if
+// one was started.
+func (v *KeyVerifier) Close() error {
+	if v.subCancel != nil {
+		v.subCancel()
+	}
+
+	return v.client.Close()
+}
+
 type KeySigningBody struct {
 	Payload KeySigningPayload `json:"payload"`
 	Hsm     KeySigningHsm     `json:"hsm"`
@@ -243,17 +232,17 @@ type KeySigningPayload struct {
 }
 
 type AccessVerificationKeyStore struct {
-	client      *redis.Client
-	verifier    *KeyVerifier
+	client      redis.UniversalClient
+	verifier    KeyAuthorityVerifier
 	timestamper encodinginterfaces.Timestamper
 }
 
+// NewAccessVerificationKeyStore builds a KeyAuthorityVerifier according to HSM_VERIFIER_BACKEND
+// ("redis", the default, or "vault") and wires it into an AccessVerificationKeyStore. Operators
+// who already run Vault can set HSM_VERIFIER_BACKEND=vault to verify access-key authorizations
+// against Vault's Transit secrets engine instead of standing up the Redis-backed HSM logger
+// KeyVerifier depends on.
 func NewAccessVerificationKeyStore(accessLifetime time.Duration) (*AccessVerificationKeyStore, error) {
-	redisHost := os.Getenv("REDIS_HOST")
-	if redisHost == "" {
-		redisHost = "redis:6379"
-	}
-
 	redisDbAccessKeysString := os.Getenv("REDIS_DB_ACCESS_KEYS")
 
 	redisDbAccessKeys, err := strconv.Atoi(redisDbAccessKeysString)
@@ -261,12 +250,17 @@ func NewAccessVerificationKeyStore(accessLifetime time.Duration) (*AccessVerific
 		return nil, err
 	}
 
-	accessClient := redis.NewClient(&redis.Options{
-		Addr: redisHost,
-		DB:   redisDbAccessKeys,
-	})
+	redisConfig, err := redisConfigFromEnv(redisDbAccessKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	accessClient, err := redisConfig.NewClient()
+	if err != nil {
+		return nil, err
+	}
 
-	verifier, err := NewKeyVerifier(accessLifetime)
+	verifier, err := keyAuthorityVerifierFromEnv(accessLifetime)
 	if err != nil {
 		return nil, err
 	}
@@ -280,6 +274,20 @@ func NewAccessVerificationKeyStore(accessLifetime time.Duration) (*AccessVerific
 	}, nil
 }
 
+// keyAuthorityVerifierFromEnv chooses between the Redis-backed KeyVerifier and the Vault-backed
+// TransitKeyVerifier based on HSM_VERIFIER_BACKEND, defaulting to "redis" for compatibility with
+// deployments that predate the Vault backend.
+func keyAuthorityVerifierFromEnv(accessLifetime time.Duration) (KeyAuthorityVerifier, error) {
+	switch backend := os.Getenv("HSM_VERIFIER_BACKEND"); backend {
+	case "", "redis":
+		return NewKeyVerifier(accessLifetime)
+	case "vault":
+		return NewTransitKeyVerifier()
+	default:
+		return nil, fmt.Errorf("unknown HSM_VERIFIER_BACKEND %q", backend)
+	}
+}
+
 func (s AccessVerificationKeyStore) Get(ctx context.Context, identity string) (cryptointerfaces.VerificationKey, error) {
 	// Retry Redis Get operation to handle connection drops gracefully
 	verificationAuthorization, err := retryRedisOperation(ctx, func() (string, error) {
@@ -336,6 +344,6 @@ func (s AccessVerificationKeyStore) Get(ctx context.Context, identity string) (c
 }
 
 func (s AccessVerificationKeyStore) CloseClients() error {
-	_ = s.verifier.client.Close()
+	_ = s.verifier.Close()
 	return s.client.Close()
 }
@@ -0,0 +1,247 @@
+package implementation
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CachedKeyHashReservationStoreMetrics is a point-in-time snapshot of
+// CachedKeyHashReservationStore's counters, shaped so a caller can feed each field straight
+// into a Prometheus CounterFunc (or any other metrics system) without further translation.
+type CachedKeyHashReservationStoreMetrics struct {
+	Hits   uint64 // Reserve calls short-circuited by a locally cached entry
+	Misses uint64 // Reserve calls that fell through to the backing store
+	Stale  uint64 // locally cached entries found expired and evicted before falling through
+}
+
+// cacheEntry is one shard's view of a key hash: it expires at expiresAt regardless of whether
+// it represents a confirmed reservation (Reserve succeeded) or a negative-cache placeholder
+// (Reserve is in flight, or the backend rejected it) — see Reserve for how the two differ.
+type cacheEntry struct {
+	expiresAt time.Time
+	element   *list.Element // this entry's node in the shard's LRU list
+}
+
+// cacheShard is one of CachedKeyHashReservationStore's stripes. Keeping a mutex per shard
+// instead of one global lock lets concurrent Reserve calls for unrelated hashes (the common
+// case under load) proceed without contending on each other.
+type cacheShard struct {
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+	lru     *list.List // front = most recently used
+	maxSize int
+}
+
+func newCacheShard(maxSize int) *cacheShard {
+	return &cacheShard{
+		entries: map[string]*cacheEntry{},
+		lru:     list.New(),
+		maxSize: maxSize,
+	}
+}
+
+// touch records keyHash as reserved/claimed until expiresAt, evicting the shard's least
+// recently used entry first if this insertion would exceed maxSize. Caller must hold mu.
+func (s *cacheShard) touch(keyHash string, expiresAt time.Time) {
+	if existing, ok := s.entries[keyHash]; ok {
+		existing.expiresAt = expiresAt
+		s.lru.MoveToFront(existing.element)
+		return
+	}
+
+	if s.maxSize > 0 && len(s.entries) >= s.maxSize {
+		oldest := s.lru.Back()
+		if oldest != nil {
+			s.lru.Remove(oldest)
+			delete(s.entries, oldest.Value.(string))
+		}
+	}
+
+	element := s.lru.PushFront(keyHash)
+	s.entries[keyHash] = &cacheEntry{expiresAt: expiresAt, element: element}
+}
+
+func (s *cacheShard) evict(keyHash string) {
+	if existing, ok := s.entries[keyHash]; ok {
+		s.lru.Remove(existing.element)
+		delete(s.entries, keyHash)
+	}
+}
+
+// CachedKeyHashReservationStore wraps a KeyHashReservationStore (normally
+// redisadapter.KeyHashReservationStore) with an in-process, sharded, TTL-bounded cache, so
+// the hot Reserve path — called on every authenticated request — doesn't round-trip Redis
+// when the answer is already known locally.
+//
+// Cache entries never outlive the backend's Lifetime(): letting a locally-cached "not yet
+// reserved" answer survive longer than the backend's own replay window would let an attacker
+// replay a request past the point the backend itself would still reject it. NewCachedKeyHashReservationStore
+// enforces ttl <= backend.Lifetime() for exactly this reason.
+type CachedKeyHashReservationStore struct {
+	backend KeyHashReservationStore
+
+	ttl         time.Duration
+	negativeTTL time.Duration
+
+	shards    []*cacheShard
+	numShards uint32
+
+	hits, misses, stale uint64 // atomic
+
+	stopSweeper chan struct{}
+}
+
+// NewCachedKeyHashReservationStore builds a cache in front of backend. ttl is how long a
+// confirmed reservation is remembered locally (must be <= backend.Lifetime()).
+// negativeCacheWindow is how long a Reserve-in-flight (or backend-rejected) key hash is
+// remembered, to collapse a burst of near-simultaneous Reserve calls for the same hash — the
+// shape a replayed request under a login storm takes — into a single backend round trip.
+// shardCount and maxEntriesPerShard bound the cache's total footprint (shardCount *
+// maxEntriesPerShard entries at most); a background sweeper runs every sweepInterval to
+// reclaim expired entries between Reserve calls so an idle cache doesn't hold memory forever.
+func NewCachedKeyHashReservationStore(
+	backend KeyHashReservationStore,
+	ttl time.Duration,
+	negativeCacheWindow time.Duration,
+	shardCount int,
+	maxEntriesPerShard int,
+	sweepInterval time.Duration,
+) (*CachedKeyHashReservationStore, error) {
+	if ttl > backend.Lifetime() {
+		return nil, fmt.Errorf("cache ttl (%s) must not exceed backend lifetime (%s)", ttl, backend.Lifetime())
+	}
+
+	if shardCount <= 0 {
+		shardCount = 1
+	}
+
+	shards := make([]*cacheShard, shardCount)
+	for i := range shards {
+		shards[i] = newCacheShard(maxEntriesPerShard)
+	}
+
+	store := &CachedKeyHashReservationStore{
+		backend:     backend,
+		ttl:         ttl,
+		negativeTTL: negativeCacheWindow,
+		shards:      shards,
+		numShards:   uint32(shardCount),
+		stopSweeper: make(chan struct{}),
+	}
+
+	if sweepInterval > 0 {
+		go store.sweepLoop(sweepInterval)
+	}
+
+	return store, nil
+}
+
+func (c *CachedKeyHashReservationStore) Close() {
+	close(c.stopSweeper)
+}
+
+func (c *CachedKeyHashReservationStore) Lifetime() time.Duration {
+	return c.backend.Lifetime()
+}
+
+// Metrics returns a snapshot of this cache's hit/miss/stale counters.
+func (c *CachedKeyHashReservationStore) Metrics() CachedKeyHashReservationStoreMetrics {
+	return CachedKeyHashReservationStoreMetrics{
+		Hits:   atomic.LoadUint64(&c.hits),
+		Misses: atomic.LoadUint64(&c.misses),
+		Stale:  atomic.LoadUint64(&c.stale),
+	}
+}
+
+func (c *CachedKeyHashReservationStore) shardFor(keyHash string) *cacheShard {
+	return c.shards[fnv32(keyHash)%c.numShards]
+}
+
+func (c *CachedKeyHashReservationStore) Reserve(ctx context.Context, keyHash string) error {
+	shard := c.shardFor(keyHash)
+	now := time.Now()
+
+	shard.mu.Lock()
+	if entry, ok := shard.entries[keyHash]; ok {
+		if now.Before(entry.expiresAt) {
+			shard.mu.Unlock()
+			atomic.AddUint64(&c.hits, 1)
+			return fmt.Errorf("already exists")
+		}
+
+		shard.evict(keyHash)
+		atomic.AddUint64(&c.stale, 1)
+	}
+
+	// Claim keyHash locally for the negative-cache window before calling the backend, so a
+	// burst of near-simultaneous Reserve calls for the same hash (e.g. a replayed request
+	// arriving multiple times during a login storm) is collapsed into this one backend call
+	// instead of each racing Redis independently.
+	shard.touch(keyHash, now.Add(c.negativeTTL))
+	shard.mu.Unlock()
+	atomic.AddUint64(&c.misses, 1)
+
+	err := c.backend.Reserve(ctx, keyHash)
+
+	shard.mu.Lock()
+	if err == nil {
+		// Confirmed reservation: remember it out to the full cache ttl so replay attempts
+		// within that window are rejected locally without hitting Redis again.
+		shard.touch(keyHash, now.Add(c.ttl))
+	} else {
+		// The backend rejected it (or the call failed transiently) — keep only the short
+		// negative-cache claim so a fresh attempt after the window re-checks the backend
+		// rather than trusting a possibly-transient failure indefinitely.
+		shard.touch(keyHash, now.Add(c.negativeTTL))
+	}
+	shard.mu.Unlock()
+
+	return err
+}
+
+func (c *CachedKeyHashReservationStore) sweepLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopSweeper:
+			return
+		case <-ticker.C:
+			c.sweep()
+		}
+	}
+}
+
+func (c *CachedKeyHashReservationStore) sweep() {
+	now := time.Now()
+
+	for _, shard := range c.shards {
+		shard.mu.Lock()
+		for keyHash, entry := range shard.entries {
+			if !now.Before(entry.expiresAt) {
+				shard.evict(keyHash)
+			}
+		}
+		shard.mu.Unlock()
+	}
+}
+
+// fnv32 is a small, dependency-free string hash used only to pick a shard — it has no
+// security role, so FNV-1a's weak collision resistance is irrelevant here.
+func fnv32(s string) uint32 {
+	const offset32 = 2166136261
+	const prime32 = 16777619
+
+	hash := uint32(offset32)
+	for i := 0; i < len(s); i++ {
+		hash ^= uint32(s[i])
+		hash *= prime32
+	}
+
+	return hash
+}
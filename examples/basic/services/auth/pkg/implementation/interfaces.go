@@ -0,0 +1,60 @@
+// Package implementation declares the persistence interfaces the auth server needs,
+// independent of any particular backend, so a deployment can swap the Redis defaults for
+// BoltDB, DynamoDB, Consul, an in-memory store for tests, or anything else that can satisfy
+// these contracts.
+package implementation
+
+import (
+	"context"
+	"time"
+)
+
+// KeyHashReservationStore records access-key hashes for the duration of Lifetime, rejecting
+// a Reserve call for a hash that's already been reserved — the one-time-use guard on access
+// keys — historically backed by a Redis SET with a TTL, but any store that can hand back
+// "have I seen this hash before, and if not, remember it for Lifetime" will do.
+type KeyHashReservationStore interface {
+	Lifetime() time.Duration
+	Reserve(ctx context.Context, keyHash string) error
+}
+
+// RefreshIdleTracker records when a refresh token/access key hash was last used, so a
+// `session/refresh` flow can enforce an idle timeout in addition to the refresh token's
+// absolute lifetime: Touch is called on every successful refresh, and IdleSince tells the
+// caller how long it's been since the last one. A zero idleTimeout means "no idle expiry",
+// matching the rest of this codebase's "0s means no expiration" convention.
+//
+// NOTE: the actual `session/refresh` enforcement point is `api.BetterAuthServer.RefreshSession`
+// in the external github.com/jasoncolburne/better-auth-go module, which isn't vendored in
+// this tree (its go.mod replace directive points at a sibling dependencies/ directory that
+// doesn't exist here). This interface and its redisadapter implementation are the storage
+// side of chunk2-2, ready for that method to call once the dependency is present.
+type RefreshIdleTracker interface {
+	// Touch records keyHash as used right now.
+	Touch(ctx context.Context, keyHash string) error
+	// IdleSince returns how long it's been since keyHash was last Touch-ed, and whether it's
+	// been touched at all (false if this is the first use, or the record expired).
+	IdleSince(ctx context.Context, keyHash string) (time.Duration, bool, error)
+}
+
+// EnforceIdleTimeout applies this codebase's "0s means no expiration" convention: a zero
+// idleTimeout always allows, as does a hash that's never been touched (its first use); a
+// touched hash must be within idleTimeout of its last use.
+func EnforceIdleTimeout(idleTimeout time.Duration, idleSince time.Duration, touched bool) bool {
+	if idleTimeout == 0 || !touched {
+		return true
+	}
+
+	return idleSince < idleTimeout
+}
+
+// EnumerableKeyHashReservationStore is a KeyHashReservationStore that can also list its
+// currently-reserved hashes along with their remaining TTL, for tooling (e.g.
+// pkg/implementation/migrate) that needs to copy reservations between backends without
+// resetting everyone's one-time-use window.
+type EnumerableKeyHashReservationStore interface {
+	KeyHashReservationStore
+
+	// Reservations returns every currently-reserved key hash mapped to its remaining TTL.
+	Reservations(ctx context.Context) (map[string]time.Duration, error)
+}
@@ -0,0 +1,319 @@
+package implementation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/algorithms"
+)
+
+// scanBatch is one page of KeyVerifier's bootstrap SCAN: the keys it turned up, and the cursor
+// to resume from (0 once the scan has wrapped back to the start).
+type scanBatch struct {
+	keys   []string
+	cursor uint64
+}
+
+// ensureBootstrapped loads every SignedLogEntry currently in Redis exactly once (subsequent
+// calls are no-ops), verifying each prefix's full chain from scratch, then starts the
+// subscription that keeps the cache current from there on. Using SCAN rather than KEYS means
+// this doesn't block the Redis server while it runs, even against a very large HSM key
+// database.
+func (v *KeyVerifier) ensureBootstrapped(ctx context.Context) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.bootstrapped {
+		return nil
+	}
+
+	byPrefix, err := v.scanAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	for prefix, records := range byPrefix {
+		slices.SortFunc(records, func(a, b *SignedLogEntry) int {
+			if a.Payload.SequenceNumber < b.Payload.SequenceNumber {
+				return -1
+			}
+
+			if a.Payload.SequenceNumber > b.Payload.SequenceNumber {
+				return 1
+			}
+
+			return 0
+		})
+
+		byPrefix[prefix] = records
+	}
+
+	// verify data & signatures
+	for _, records := range byPrefix {
+		for _, record := range records {
+			payload := record.Payload
+
+			if payload.SequenceNumber == 0 {
+				if err := algorithms.VerifyPrefixAndData(&payload); err != nil {
+					return err
+				}
+			} else {
+				if err := algorithms.VerifyAddressAndData(&payload); err != nil {
+					return err
+				}
+			}
+
+			message, err := json.Marshal(payload)
+			if err != nil {
+				return err
+			}
+
+			suite := v.registry.Lookup(payload.PublicKey)
+			if err := suite.Verifier.Verify(record.Signature, payload.PublicKey, message); err != nil {
+				return err
+			}
+		}
+	}
+
+	// verify chains, remembering each prefix's tail so a later incremental record can be
+	// checked against just it
+	for prefix, records := range byPrefix {
+		lastId := ""
+		lastRotationHash := ""
+		for i, record := range records {
+			payload := record.Payload
+
+			if int(payload.SequenceNumber) != i {
+				return fmt.Errorf("bad sequence number")
+			}
+
+			if payload.SequenceNumber != 0 {
+				if lastId != *payload.Previous {
+					return fmt.Errorf("broken chain")
+				}
+
+				suite := v.registry.Lookup(lastRotationHash)
+				hash := suite.TagValue(suite.Hasher.Sum([]byte(payload.PublicKey)))
+
+				if !strings.EqualFold(hash, lastRotationHash) {
+					return fmt.Errorf("bad commitment")
+				}
+			}
+
+			lastId = payload.Id
+			lastRotationHash = payload.RotationHash
+		}
+
+		if len(records) > 0 {
+			last := records[len(records)-1].Payload
+			v.chains[prefix] = &prefixChainState{
+				lastSequenceNumber: last.SequenceNumber,
+				lastId:             last.Id,
+				lastRotationHash:   last.RotationHash,
+			}
+		}
+	}
+
+	// populate the generation-id cache from the HSM identity's own chain, walking backward no
+	// further than a restarted pod could plausibly still need
+	records, ok := byPrefix[HSM_IDENTITY]
+	if !ok {
+		return fmt.Errorf("hsm identity not found")
+	}
+
+	for i := len(records) - 1; i >= 0; i-- {
+		payload := records[i].Payload
+		v.cacheEntryLocked(&payload)
+
+		when := (time.Time)(*payload.CreatedAt)
+		if when.Add(v.accessLifetime + keyVerifierRestartThreshold).Before(time.Now()) {
+			break
+		}
+	}
+
+	v.bootstrapped = true
+
+	subCtx, cancel := context.WithCancel(context.Background())
+	v.subCancel = cancel
+	go v.subscribeLoop(subCtx)
+
+	return nil
+}
+
+// scanAll pages through every key in v.client using SCAN (batches of v.scanBatchSize) and MGET,
+// grouping the resulting SignedLogEntry records by prefix.
+func (v *KeyVerifier) scanAll(ctx context.Context) (map[string][]*SignedLogEntry, error) {
+	byPrefix := map[string][]*SignedLogEntry{}
+
+	var cursor uint64
+	for {
+		batch, err := retryRedisOperation(ctx, func() (scanBatch, error) {
+			keys, nextCursor, err := v.client.Scan(ctx, cursor, "*", v.scanBatchSize).Result()
+			return scanBatch{keys: keys, cursor: nextCursor}, err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(batch.keys) > 0 {
+			values, err := retryRedisOperation(ctx, func() ([]any, error) {
+				return v.client.MGet(ctx, batch.keys...).Result()
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			for _, value := range values {
+				if value == nil {
+					continue
+				}
+
+				bytes, ok := value.(string)
+				if !ok {
+					return nil, fmt.Errorf("unexpected type for record")
+				}
+
+				record := &SignedLogEntry{}
+				if err := json.Unmarshal([]byte(bytes), record); err != nil {
+					return nil, err
+				}
+
+				prefix := record.Payload.Prefix
+				byPrefix[prefix] = append(byPrefix[prefix], record)
+			}
+		}
+
+		cursor = batch.cursor
+		if cursor == 0 {
+			break
+		}
+	}
+
+	return byPrefix, nil
+}
+
+// subscribeLoop keeps the cache current after bootstrap by listening on v.channel for new
+// SignedLogEntry records the HSM writer publishes, verifying and appending only that new tail
+// instead of re-scanning Redis. It runs until ctx is cancelled (by Close).
+func (v *KeyVerifier) subscribeLoop(ctx context.Context) {
+	pubsub := v.client.Subscribe(ctx, v.channel)
+	defer pubsub.Close()
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+
+			record := &SignedLogEntry{}
+			if err := json.Unmarshal([]byte(msg.Payload), record); err != nil {
+				log.Printf("key verifier: malformed log entry on %s: %v", v.channel, err)
+				continue
+			}
+
+			if err := v.ingest(record); err != nil {
+				log.Printf("key verifier: rejected incoming log entry: %v", err)
+			}
+		}
+	}
+}
+
+// ingest verifies a single newly-published SignedLogEntry against the chain state recorded for
+// its prefix, then appends it — re-running VerifyAddressAndData/VerifyPrefixAndData, the
+// signature check, and the chain link, but none of the prefix's earlier history.
+func (v *KeyVerifier) ingest(record *SignedLogEntry) error {
+	payload := record.Payload
+
+	if payload.SequenceNumber == 0 {
+		if err := algorithms.VerifyPrefixAndData(&payload); err != nil {
+			return err
+		}
+	} else {
+		if err := algorithms.VerifyAddressAndData(&payload); err != nil {
+			return err
+		}
+	}
+
+	message, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	signatureSuite := v.registry.Lookup(payload.PublicKey)
+	if err := signatureSuite.Verifier.Verify(record.Signature, payload.PublicKey, message); err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	state, ok := v.chains[payload.Prefix]
+	if !ok {
+		if payload.SequenceNumber != 0 {
+			return fmt.Errorf("no known chain for prefix %q to append to", payload.Prefix)
+		}
+	} else {
+		if payload.SequenceNumber != state.lastSequenceNumber+1 {
+			return fmt.Errorf("bad sequence number")
+		}
+
+		if payload.Previous == nil || *payload.Previous != state.lastId {
+			return fmt.Errorf("broken chain")
+		}
+
+		commitmentSuite := v.registry.Lookup(state.lastRotationHash)
+		hash := commitmentSuite.TagValue(commitmentSuite.Hasher.Sum([]byte(payload.PublicKey)))
+		if !strings.EqualFold(hash, state.lastRotationHash) {
+			return fmt.Errorf("bad commitment")
+		}
+	}
+
+	v.chains[payload.Prefix] = &prefixChainState{
+		lastSequenceNumber: payload.SequenceNumber,
+		lastId:             payload.Id,
+		lastRotationHash:   payload.RotationHash,
+	}
+
+	if payload.Prefix == HSM_IDENTITY {
+		v.cacheEntryLocked(&payload)
+	}
+
+	return nil
+}
+
+// cacheEntryLocked inserts or refreshes payload in the generation-id cache, evicting the least
+// recently inserted/updated entry first if this would exceed maxEntries. Caller must hold mu.
+func (v *KeyVerifier) cacheEntryLocked(payload *LogEntry) {
+	expiresAt := (time.Time)(*payload.CreatedAt).Add(v.accessLifetime + keyVerifierRestartThreshold)
+
+	if existing, ok := v.entries[payload.Id]; ok {
+		existing.payload = payload
+		existing.expiresAt = expiresAt
+		v.lru.MoveToFront(existing.element)
+		return
+	}
+
+	if v.maxEntries > 0 && len(v.entries) >= v.maxEntries {
+		oldest := v.lru.Back()
+		if oldest != nil {
+			v.lru.Remove(oldest)
+			delete(v.entries, oldest.Value.(string))
+		}
+	}
+
+	element := v.lru.PushFront(payload.Id)
+	v.entries[payload.Id] = &cachedLogEntry{
+		payload:   payload,
+		expiresAt: expiresAt,
+		element:   element,
+	}
+}
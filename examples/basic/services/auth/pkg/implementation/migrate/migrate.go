@@ -0,0 +1,56 @@
+// Package migrate copies reservations from one EnumerableKeyHashReservationStore into
+// another KeyHashReservationStore — e.g. to reshard Redis or move onto a different backend
+// entirely — without resetting the one-time-use window on hashes that are already reserved.
+package migrate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/implementation"
+)
+
+// Options configures a single Run.
+type Options struct {
+	// DryRun reports what would be written without calling dest.Reserve.
+	DryRun bool
+}
+
+// Result summarizes a completed or partial Run.
+type Result struct {
+	Seen     int
+	Migrated int
+}
+
+// Run lists every reservation in source and re-reserves each one in dest, skipping any hash
+// whose TTL has already lapsed by the time Run gets to it. dest.Reserve applies dest's own
+// configured Lifetime rather than source's remaining TTL, since KeyHashReservationStore has
+// no "reserve with explicit TTL" operation — callers migrating onto a backend with a
+// different Lifetime should account for that themselves.
+func Run(ctx context.Context, source implementation.EnumerableKeyHashReservationStore, dest implementation.KeyHashReservationStore, opts Options) (Result, error) {
+	reservations, err := source.Reservations(ctx)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to read source reservations: %w", err)
+	}
+
+	var result Result
+
+	for keyHash, ttl := range reservations {
+		result.Seen++
+
+		if ttl <= 0 {
+			continue
+		}
+
+		if opts.DryRun {
+			continue
+		}
+
+		if err := dest.Reserve(ctx, keyHash); err != nil {
+			return result, fmt.Errorf("failed to reserve %q on destination: %w", keyHash, err)
+		}
+		result.Migrated++
+	}
+
+	return result, nil
+}
@@ -0,0 +1,132 @@
+// Package redisadapter is the default implementation.KeyHashReservationStore, backed by
+// Redis.
+package redisadapter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type KeyHashReservationStore struct {
+	lifetime time.Duration
+	client   *redis.Client
+}
+
+func NewKeyHashReservationStore(lifetime time.Duration) (*KeyHashReservationStore, error) {
+	redisHost := os.Getenv("REDIS_HOST")
+	if redisHost == "" {
+		redisHost = "redis:6379"
+	}
+
+	redisDbAccessKeyHashString := os.Getenv("REDIS_DB_ACCESS_KEYHASH")
+
+	redisDbAccessKeyHash, err := strconv.Atoi(redisDbAccessKeyHashString)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewKeyHashReservationStoreFromAddr(redisHost, redisDbAccessKeyHash, lifetime), nil
+}
+
+// NewKeyHashReservationStoreFromAddr builds a KeyHashReservationStore against an explicit
+// Redis address and DB, for callers (e.g. cmd/better-auth-migrate) that need to address a
+// source and a destination independently of this process's own REDIS_HOST/REDIS_DB_ACCESS_KEYHASH.
+func NewKeyHashReservationStoreFromAddr(addr string, db int, lifetime time.Duration) *KeyHashReservationStore {
+	client := redis.NewClient(&redis.Options{
+		Addr: addr,
+		DB:   db,
+	})
+
+	return &KeyHashReservationStore{
+		lifetime: lifetime,
+		client:   client,
+	}
+}
+
+func (s KeyHashReservationStore) Lifetime() time.Duration {
+	return s.lifetime
+}
+
+// Reservations scans every key in this store's Redis DB and reports its remaining TTL. It's
+// meant for migration tooling, not the request path — a full SCAN is too slow to sit in
+// front of Reserve.
+func (s KeyHashReservationStore) Reservations(ctx context.Context) (map[string]time.Duration, error) {
+	reservations := map[string]time.Duration{}
+
+	keys, err := retryRedisOperation(ctx, func() ([]string, error) {
+		return s.client.Keys(ctx, "*").Result()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		ttl, err := retryRedisOperation(ctx, func() (time.Duration, error) {
+			return s.client.TTL(ctx, key).Result()
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		reservations[key] = ttl
+	}
+
+	return reservations, nil
+}
+
+func (s KeyHashReservationStore) Reserve(ctx context.Context, keyHash string) error {
+	// Retry Redis Exists operation to handle connection drops gracefully
+	exists, err := retryRedisOperation(ctx, func() (int64, error) {
+		return s.client.Exists(ctx, keyHash).Result()
+	})
+	if err != nil {
+		return err
+	}
+
+	if exists > 0 {
+		return fmt.Errorf("already exists")
+	}
+
+	// Retry Redis Set operation to handle connection drops gracefully
+	_, err = retryRedisOperation(ctx, func() (struct{}, error) {
+		return struct{}{}, s.client.Set(ctx, keyHash, true, s.lifetime).Err()
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// retryRedisOperation executes a Redis operation with retry logic and exponential backoff.
+// This ensures graceful recovery when Redis restarts or connections drop.
+func retryRedisOperation[T any](ctx context.Context, operation func() (T, error)) (T, error) {
+	const maxRetries = 3
+	const initialBackoff = 100 * time.Millisecond
+
+	var lastErr error
+	var zero T
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		if attempt > 0 {
+			// Exponential backoff: 100ms, 200ms, 400ms
+			backoff := initialBackoff * time.Duration(1<<uint(attempt-1))
+			time.Sleep(backoff)
+		}
+
+		result, err := operation()
+		if err != nil {
+			lastErr = err
+			continue // Retry
+		}
+
+		return result, nil
+	}
+
+	return zero, fmt.Errorf("redis operation failed after %d retries: %w", maxRetries, lastErr)
+}
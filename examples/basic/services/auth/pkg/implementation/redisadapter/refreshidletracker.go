@@ -0,0 +1,66 @@
+package redisadapter
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RefreshIdleTracker is the default implementation.RefreshIdleTracker, backed by Redis: each
+// Touch resets a key's TTL to lifetime, so a hash that's gone idle past lifetime simply
+// expires out of Redis and IdleSince reports it as never touched.
+type RefreshIdleTracker struct {
+	lifetime time.Duration
+	client   *redis.Client
+}
+
+func NewRefreshIdleTracker(lifetime time.Duration) (*RefreshIdleTracker, error) {
+	redisHost := os.Getenv("REDIS_HOST")
+	if redisHost == "" {
+		redisHost = "redis:6379"
+	}
+
+	redisDbRefreshIdleString := os.Getenv("REDIS_DB_REFRESH_IDLE")
+	redisDbRefreshIdle, err := strconv.Atoi(redisDbRefreshIdleString)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RefreshIdleTracker{
+		lifetime: lifetime,
+		client: redis.NewClient(&redis.Options{
+			Addr: redisHost,
+			DB:   redisDbRefreshIdle,
+		}),
+	}, nil
+}
+
+func (t *RefreshIdleTracker) Touch(ctx context.Context, keyHash string) error {
+	_, err := retryRedisOperation(ctx, func() (struct{}, error) {
+		return struct{}{}, t.client.Set(ctx, keyHash, time.Now().UTC().Format(time.RFC3339Nano), t.lifetime).Err()
+	})
+
+	return err
+}
+
+func (t *RefreshIdleTracker) IdleSince(ctx context.Context, keyHash string) (time.Duration, bool, error) {
+	lastUsedString, err := retryRedisOperation(ctx, func() (string, error) {
+		return t.client.Get(ctx, keyHash).Result()
+	})
+	if err == redis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	lastUsed, err := time.Parse(time.RFC3339Nano, lastUsedString)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return time.Since(lastUsed), true, nil
+}
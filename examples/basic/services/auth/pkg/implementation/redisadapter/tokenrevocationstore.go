@@ -0,0 +1,139 @@
+package redisadapter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TokenRevocationStore is the default store.TokenRevocationStore, backed by Redis: Revoke
+// sets tokenHash with a TTL equal to its remaining lifetime, so an entry never outlives the
+// token it denylists and needs no separate background GC pass.
+//
+// It also tracks which token hashes belong to which (identity, device) pair, in a Redis set
+// keyed by identity/device, so RevokeAllForDevice and RevokeAllForIdentity can revoke every
+// outstanding token tied to a device or identity without this package needing to know how
+// tokens are issued. A caller (e.g. the access-verification path, if this process's were
+// locally reachable rather than living inside the unvendored better-auth-go dependency) is
+// expected to call Track once per issued token.
+type TokenRevocationStore struct {
+	client *redis.Client
+}
+
+func NewTokenRevocationStore() (*TokenRevocationStore, error) {
+	redisHost := os.Getenv("REDIS_HOST")
+	if redisHost == "" {
+		redisHost = "redis:6379"
+	}
+
+	redisDbTokenRevocationsString := os.Getenv("REDIS_DB_TOKEN_REVOCATIONS")
+
+	redisDbTokenRevocations, err := strconv.Atoi(redisDbTokenRevocationsString)
+	if err != nil {
+		return nil, err
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr: redisHost,
+		DB:   redisDbTokenRevocations,
+	})
+
+	return &TokenRevocationStore{
+		client: client,
+	}, nil
+}
+
+func (s TokenRevocationStore) Revoke(ctx context.Context, tokenHash string, exp time.Time) error {
+	ttl := time.Until(exp)
+	if ttl <= 0 {
+		// already expired; nothing left to deny
+		return nil
+	}
+
+	_, err := retryRedisOperation(ctx, func() (struct{}, error) {
+		return struct{}{}, s.client.Set(ctx, tokenHash, true, ttl).Err()
+	})
+
+	return err
+}
+
+func (s TokenRevocationStore) IsRevoked(ctx context.Context, tokenHash string) (bool, error) {
+	exists, err := retryRedisOperation(ctx, func() (int64, error) {
+		return s.client.Exists(ctx, tokenHash).Result()
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return exists > 0, nil
+}
+
+// scope builds the secondary set's key for (identity, device). device == "" scopes to every
+// device belonging to identity, for RevokeAllForIdentity.
+func scope(identity, device string) string {
+	return fmt.Sprintf("scope/%s/%s", identity, device)
+}
+
+// Track records that tokenHash was issued to (identity, device) and expires at exp, so a
+// later RevokeAllForDevice or RevokeAllForIdentity can find and revoke it. It adds tokenHash
+// to both the device-scoped and identity-scoped sets; the set itself is given a generous TTL
+// refresh so it doesn't outlive every token it could ever reference.
+func (s TokenRevocationStore) Track(ctx context.Context, identity, device, tokenHash string, exp time.Time) error {
+	ttl := time.Until(exp)
+	if ttl <= 0 {
+		return nil
+	}
+
+	for _, key := range []string{scope(identity, device), scope(identity, "")} {
+		if _, err := retryRedisOperation(ctx, func() (struct{}, error) {
+			return struct{}{}, s.client.SAdd(ctx, key, tokenHash).Err()
+		}); err != nil {
+			return err
+		}
+
+		if _, err := retryRedisOperation(ctx, func() (struct{}, error) {
+			return struct{}{}, s.client.Expire(ctx, key, ttl).Err()
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RevokeAllForDevice revokes every token Track has recorded for (identity, device), the way
+// AuthenticationKeyStore.RevokeDevice retires that device's authentication key.
+func (s TokenRevocationStore) RevokeAllForDevice(ctx context.Context, identity, device string, exp time.Time) error {
+	return s.revokeScope(ctx, scope(identity, device), exp)
+}
+
+// RevokeAllForIdentity revokes every token Track has recorded for identity across every
+// device, the way AuthenticationKeyStore.DeleteIdentity retires every device's key at once.
+func (s TokenRevocationStore) RevokeAllForIdentity(ctx context.Context, identity string, exp time.Time) error {
+	return s.revokeScope(ctx, scope(identity, ""), exp)
+}
+
+func (s TokenRevocationStore) revokeScope(ctx context.Context, key string, exp time.Time) error {
+	tokenHashes, err := retryRedisOperation(ctx, func() ([]string, error) {
+		return s.client.SMembers(ctx, key).Result()
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, tokenHash := range tokenHashes {
+		if err := s.Revoke(ctx, tokenHash, exp); err != nil {
+			return err
+		}
+	}
+
+	_, err = retryRedisOperation(ctx, func() (struct{}, error) {
+		return struct{}{}, s.client.Del(ctx, key).Err()
+	})
+
+	return err
+}
@@ -0,0 +1,293 @@
+package implementation
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisMode selects which Redis deployment topology RedisConfig.NewClient builds a client for.
+type RedisMode string
+
+const (
+	RedisModeStandalone RedisMode = "standalone"
+	RedisModeSentinel   RedisMode = "sentinel"
+	RedisModeCluster    RedisMode = "cluster"
+)
+
+// RedisTLSConfig carries the TLS settings RedisConfig needs to dial Redis over TLS: a CA file
+// to validate the server's certificate, an optional client cert/key pair for mutual TLS, and
+// the server name used for SNI/hostname verification.
+type RedisTLSConfig struct {
+	Enabled    bool
+	CAFile     string
+	CertFile   string
+	KeyFile    string
+	ServerName string
+}
+
+func (t RedisTLSConfig) build() (*tls.Config, error) {
+	if !t.Enabled {
+		return nil, nil
+	}
+
+	config := &tls.Config{ServerName: t.ServerName}
+
+	if t.CAFile != "" {
+		caCert, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read redis CA file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("failed to parse redis CA file %q", t.CAFile)
+		}
+
+		config.RootCAs = pool
+	}
+
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load redis client certificate: %w", err)
+		}
+
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// RedisConfig captures everything needed to build a redis.UniversalClient against any of the
+// three topologies this codebase supports, replacing the ad-hoc REDIS_HOST env var read that
+// used to live in every constructor that talked to Redis.
+type RedisConfig struct {
+	Mode RedisMode
+	// Addrs is the node list NewClient dials: exactly one host:port for Mode ==
+	// RedisModeStandalone, every sentinel's host:port for RedisModeSentinel, or every seed
+	// node for RedisModeCluster.
+	Addrs      []string
+	MasterName string // required for RedisModeSentinel; ignored otherwise
+	DB         int    // ignored for RedisModeCluster, which has no per-node db selection
+	Username   string
+	Password   string
+
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+
+	MaxRetries      int
+	MinRetryBackoff time.Duration
+	MaxRetryBackoff time.Duration
+
+	TLS RedisTLSConfig
+}
+
+// NewClient builds a redis.UniversalClient for c.Mode: a FailoverClient for sentinel, a
+// ClusterClient for cluster, or a plain Client for standalone. All three satisfy
+// redis.UniversalClient, so callers (and retryRedisOperation) don't need to know which one
+// they got.
+func (c RedisConfig) NewClient() (redis.UniversalClient, error) {
+	tlsConfig, err := c.TLS.build()
+	if err != nil {
+		return nil, err
+	}
+
+	switch c.Mode {
+	case RedisModeSentinel:
+		if c.MasterName == "" {
+			return nil, fmt.Errorf("redis sentinel mode requires a master name")
+		}
+
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:      c.MasterName,
+			SentinelAddrs:   c.Addrs,
+			DB:              c.DB,
+			Username:        c.Username,
+			Password:        c.Password,
+			DialTimeout:     c.DialTimeout,
+			ReadTimeout:     c.ReadTimeout,
+			WriteTimeout:    c.WriteTimeout,
+			MaxRetries:      c.MaxRetries,
+			MinRetryBackoff: c.MinRetryBackoff,
+			MaxRetryBackoff: c.MaxRetryBackoff,
+			TLSConfig:       tlsConfig,
+		}), nil
+
+	case RedisModeCluster:
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:           c.Addrs,
+			Username:        c.Username,
+			Password:        c.Password,
+			DialTimeout:     c.DialTimeout,
+			ReadTimeout:     c.ReadTimeout,
+			WriteTimeout:    c.WriteTimeout,
+			MaxRetries:      c.MaxRetries,
+			MinRetryBackoff: c.MinRetryBackoff,
+			MaxRetryBackoff: c.MaxRetryBackoff,
+			TLSConfig:       tlsConfig,
+		}), nil
+
+	case "", RedisModeStandalone:
+		addr := "redis:6379"
+		if len(c.Addrs) > 0 {
+			addr = c.Addrs[0]
+		}
+
+		return redis.NewClient(&redis.Options{
+			Addr:            addr,
+			DB:              c.DB,
+			Username:        c.Username,
+			Password:        c.Password,
+			DialTimeout:     c.DialTimeout,
+			ReadTimeout:     c.ReadTimeout,
+			WriteTimeout:    c.WriteTimeout,
+			MaxRetries:      c.MaxRetries,
+			MinRetryBackoff: c.MinRetryBackoff,
+			MaxRetryBackoff: c.MaxRetryBackoff,
+			TLSConfig:       tlsConfig,
+		}), nil
+
+	default:
+		return nil, fmt.Errorf("unknown redis mode %q", c.Mode)
+	}
+}
+
+// redisConfigFromEnv builds a RedisConfig for db from the REDIS_* environment variables shared
+// across this package's Redis-backed stores: REDIS_MODE ("standalone", the default,
+// "sentinel", or "cluster"), REDIS_ADDRS (comma-separated host:port list; falls back to the
+// single-node REDIS_HOST for standalone deployments that predate multi-node support),
+// REDIS_SENTINEL_MASTER, REDIS_USERNAME/REDIS_PASSWORD, REDIS_*_TIMEOUT_SECONDS,
+// REDIS_MAX_RETRIES, REDIS_MIN/MAX_RETRY_BACKOFF_MS, and REDIS_TLS_* for transport security.
+func redisConfigFromEnv(db int) (RedisConfig, error) {
+	mode := RedisMode(os.Getenv("REDIS_MODE"))
+	if mode == "" {
+		mode = RedisModeStandalone
+	}
+
+	addrs := splitRedisAddrs(os.Getenv("REDIS_ADDRS"))
+	if len(addrs) == 0 {
+		redisHost := os.Getenv("REDIS_HOST")
+		if redisHost == "" {
+			redisHost = "redis:6379"
+		}
+		addrs = []string{redisHost}
+	}
+
+	dialTimeout, err := redisDurationSecondsFromEnv("REDIS_DIAL_TIMEOUT_SECONDS", 5*time.Second)
+	if err != nil {
+		return RedisConfig{}, err
+	}
+
+	readTimeout, err := redisDurationSecondsFromEnv("REDIS_READ_TIMEOUT_SECONDS", 3*time.Second)
+	if err != nil {
+		return RedisConfig{}, err
+	}
+
+	writeTimeout, err := redisDurationSecondsFromEnv("REDIS_WRITE_TIMEOUT_SECONDS", 3*time.Second)
+	if err != nil {
+		return RedisConfig{}, err
+	}
+
+	maxRetries, err := redisIntFromEnv("REDIS_MAX_RETRIES", 3)
+	if err != nil {
+		return RedisConfig{}, err
+	}
+
+	minRetryBackoff, err := redisDurationMillisFromEnv("REDIS_MIN_RETRY_BACKOFF_MS", 100*time.Millisecond)
+	if err != nil {
+		return RedisConfig{}, err
+	}
+
+	maxRetryBackoff, err := redisDurationMillisFromEnv("REDIS_MAX_RETRY_BACKOFF_MS", 2*time.Second)
+	if err != nil {
+		return RedisConfig{}, err
+	}
+
+	return RedisConfig{
+		Mode:            mode,
+		Addrs:           addrs,
+		MasterName:      os.Getenv("REDIS_SENTINEL_MASTER"),
+		DB:              db,
+		Username:        os.Getenv("REDIS_USERNAME"),
+		Password:        os.Getenv("REDIS_PASSWORD"),
+		DialTimeout:     dialTimeout,
+		ReadTimeout:     readTimeout,
+		WriteTimeout:    writeTimeout,
+		MaxRetries:      maxRetries,
+		MinRetryBackoff: minRetryBackoff,
+		MaxRetryBackoff: maxRetryBackoff,
+		TLS: RedisTLSConfig{
+			Enabled:    os.Getenv("REDIS_TLS_ENABLED") == "true",
+			CAFile:     os.Getenv("REDIS_TLS_CA_FILE"),
+			CertFile:   os.Getenv("REDIS_TLS_CERT_FILE"),
+			KeyFile:    os.Getenv("REDIS_TLS_KEY_FILE"),
+			ServerName: os.Getenv("REDIS_TLS_SERVER_NAME"),
+		},
+	}, nil
+}
+
+func splitRedisAddrs(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+
+	parts := strings.Split(raw, ",")
+	addrs := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if trimmed := strings.TrimSpace(part); trimmed != "" {
+			addrs = append(addrs, trimmed)
+		}
+	}
+
+	return addrs
+}
+
+func redisDurationSecondsFromEnv(name string, fallback time.Duration) (time.Duration, error) {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return fallback, nil
+	}
+
+	seconds, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+
+	return time.Duration(seconds) * time.Second, nil
+}
+
+func redisDurationMillisFromEnv(name string, fallback time.Duration) (time.Duration, error) {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return fallback, nil
+	}
+
+	millis, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+
+	return time.Duration(millis) * time.Millisecond, nil
+}
+
+func redisIntFromEnv(name string, fallback int) (int, error) {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return fallback, nil
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+
+	return value, nil
+}
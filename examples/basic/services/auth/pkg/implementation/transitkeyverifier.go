@@ -0,0 +1,128 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/jasoncolburne/better-auth-go/examples/crypto"
+	"github.com/jasoncolburne/better-auth-go/pkg/cryptointerfaces"
+)
+
+// KeyAuthorityVerifier checks that signature over message was produced by the access key whose
+// authorization chains back to hsmIdentity/hsmGenerationId, letting AccessVerificationKeyStore
+// be built against either backend that can answer "what's the public key behind this HSM
+// identity and generation" — the Redis-resident SignedLogEntry chain (KeyVerifier) or a
+// HashiCorp Vault Transit key (TransitKeyVerifier).
+type KeyAuthorityVerifier interface {
+	Verify(ctx context.Context, signature, hsmIdentity, hsmGenerationId string, message []byte) error
+	// Close releases any resources (a Redis client, a Vault HTTP client) held by the verifier.
+	Close() error
+}
+
+// TransitKeyVerifier is the Vault-backed KeyAuthorityVerifier: it resolves hsmIdentity to a
+// Transit key name and hsmGenerationId to a key version within it, fetches that version's
+// public key from Vault's Transit secrets engine, and caches it, so operators who already run
+// Vault can avoid standing up the bespoke HSM logger service KeyVerifier depends on.
+type TransitKeyVerifier struct {
+	client    *api.Client
+	mountPath string
+	verifier  cryptointerfaces.Verifier
+
+	mu    sync.RWMutex
+	cache map[string]string // transitCacheKey(hsmIdentity, hsmGenerationId) -> public key
+}
+
+// NewTransitKeyVerifier builds a TransitKeyVerifier from the standard VAULT_ADDR/VAULT_TOKEN
+// environment variables the Vault API client already understands, plus VAULT_TRANSIT_MOUNT
+// (defaulting to "transit") for deployments that mount the Transit engine somewhere else.
+func NewTransitKeyVerifier() (*TransitKeyVerifier, error) {
+	config := api.DefaultConfig()
+	if err := config.ReadEnvironment(); err != nil {
+		return nil, fmt.Errorf("failed to read vault environment: %w", err)
+	}
+
+	client, err := api.NewClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vault client: %w", err)
+	}
+
+	mountPath := os.Getenv("VAULT_TRANSIT_MOUNT")
+	if mountPath == "" {
+		mountPath = "transit"
+	}
+
+	return &TransitKeyVerifier{
+		client:    client,
+		mountPath: mountPath,
+		verifier:  crypto.NewSecp256r1Verifier(),
+		cache:     map[string]string{},
+	}, nil
+}
+
+func transitCacheKey(hsmIdentity, hsmGenerationId string) string {
+	return hsmIdentity + "/" + hsmGenerationId
+}
+
+// publicKey resolves hsmIdentity/hsmGenerationId to a PEM-encoded public key, reading Vault's
+// `transit/keys/<name>` endpoint (whose `keys` map is indexed by version number, i.e.
+// hsmGenerationId) on a cache miss.
+func (v *TransitKeyVerifier) publicKey(ctx context.Context, hsmIdentity, hsmGenerationId string) (string, error) {
+	cacheKey := transitCacheKey(hsmIdentity, hsmGenerationId)
+
+	v.mu.RLock()
+	publicKey, ok := v.cache[cacheKey]
+	v.mu.RUnlock()
+	if ok {
+		return publicKey, nil
+	}
+
+	secret, err := v.client.Logical().ReadWithContext(ctx, fmt.Sprintf("%s/keys/%s", v.mountPath, hsmIdentity))
+	if err != nil {
+		return "", fmt.Errorf("failed to read transit key %q: %w", hsmIdentity, err)
+	}
+	if secret == nil || secret.Data == nil {
+		return "", fmt.Errorf("transit key %q not found", hsmIdentity)
+	}
+
+	keys, ok := secret.Data["keys"].(map[string]any)
+	if !ok {
+		return "", fmt.Errorf("unexpected response shape for transit key %q", hsmIdentity)
+	}
+
+	version, ok := keys[hsmGenerationId].(map[string]any)
+	if !ok {
+		return "", fmt.Errorf("transit key %q has no version %q", hsmIdentity, hsmGenerationId)
+	}
+
+	publicKey, ok = version["public_key"].(string)
+	if !ok || publicKey == "" {
+		return "", fmt.Errorf("transit key %q version %q has no public key", hsmIdentity, hsmGenerationId)
+	}
+
+	v.mu.Lock()
+	v.cache[cacheKey] = publicKey
+	v.mu.Unlock()
+
+	return publicKey, nil
+}
+
+func (v *TransitKeyVerifier) Verify(ctx context.Context, signature, hsmIdentity, hsmGenerationId string, message []byte) error {
+	publicKey, err := v.publicKey(ctx, hsmIdentity, hsmGenerationId)
+	if err != nil {
+		return err
+	}
+
+	return v.verifier.Verify(signature, publicKey, message)
+}
+
+// Close is a no-op: Vault's API client has no long-lived connection worth closing explicitly.
+// Present to satisfy KeyAuthorityVerifier.
+func (v *TransitKeyVerifier) Close() error {
+	return nil
+}
+
+var _ KeyAuthorityVerifier = (*KeyVerifier)(nil)
+var _ KeyAuthorityVerifier = (*TransitKeyVerifier)(nil)
@@ -1,8 +1,9 @@
 package implementation
 
 import (
-	"github.com/jasoncolburne/better-auth-go/examples/crypto"
 	"github.com/jasoncolburne/better-auth-go/pkg/cryptointerfaces"
+
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/cryptosuite"
 )
 
 type VerificationKey struct {
@@ -10,11 +11,14 @@ type VerificationKey struct {
 	publicKey string
 }
 
+// NewVerificationKey dispatches publicKey's algorithm tag through cryptosuite.Default to find
+// the Verifier that understands it, defaulting to the legacy secp256r1 suite for untagged
+// keys.
 func NewVerificationKey(publicKey string) *VerificationKey {
-	verifier := crypto.NewSecp256r1Verifier()
+	suite := cryptosuite.Default.Lookup(publicKey)
 
 	return &VerificationKey{
-		verifier:  verifier,
+		verifier:  suite.Verifier,
 		publicKey: publicKey,
 	}
 }
@@ -0,0 +1,59 @@
+package kel
+
+import "github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
+
+// EventType tags an Event the way a KERI Key Event Log tags its events. The underlying
+// verifiable-storage chain already gives us a SAID per version (Id), a link to the prior
+// event (Previous), and a per-prefix sequence number, so an Event is mostly that plus a
+// type tag and the same public-key/rotation-hash commitment the other stores already carry.
+type EventType string
+
+const (
+	EventInception         EventType = "icp" // identity inception
+	EventRotation          EventType = "rot" // authoritative key rotation
+	EventInteraction       EventType = "ixn" // non-rotating anchor event
+	EventReceipt           EventType = "rct" // witness receipt of another event
+	EventDelegatedRotation EventType = "drt" // rotation delegated to another identity
+	EventRevocation        EventType = "rvk" // revocation of the identity
+)
+
+const EVENTS_TABLE_SQL = `
+	CREATE TABLE IF NOT EXISTS kel_events (
+		-- Standard fields
+		id              	TEXT PRIMARY KEY,
+		prefix				TEXT NOT NULL,
+		previous        	TEXT,
+		sequence_number 	BIGINT NOT NULL,
+
+		-- Optional fields
+		created_at          TIMESTAMP NOT NULL,
+
+		-- Model-specific fields
+		event_type			TEXT NOT NULL,
+		public_key			TEXT NOT NULL,
+		rotation_hash       TEXT NOT NULL,
+
+		-- Uniqueness constraint for sequence numbers
+		UNIQUE(prefix, sequence_number)
+	);
+`
+
+// Event is one entry in an identity's Key Event Log. Id is the SAID of the event: the
+// verifiable-storage layer computes it over the canonical CESR-serialized record with Id
+// itself zeroed, exactly as KERI defines a self-addressing identifier.
+type Event struct {
+	primitives.VerifiableRecorder
+	EventType    EventType `db:"event_type" json:"eventType"`
+	PublicKey    string    `db:"public_key" json:"publicKey"`
+	RotationHash string    `db:"rotation_hash" json:"rotationHash"`
+}
+
+func (*Event) TableName() string {
+	return "kel_events"
+}
+
+// establishing reports whether an event type introduces a new signing key that must be
+// backed by witness receipts before it is trusted.
+func (t EventType) establishing() bool {
+	return t == EventRotation || t == EventDelegatedRotation
+}
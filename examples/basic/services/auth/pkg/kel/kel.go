@@ -0,0 +1,131 @@
+package kel
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jasoncolburne/better-auth-go/examples/crypto"
+	"github.com/jasoncolburne/better-auth-go/pkg/cryptointerfaces"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/expressions"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/orderings"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/repository"
+)
+
+// KEL is an append-only Key Event Log for a single identity prefix, unifying what the
+// Identity/AuthenticationKeys/RecoveryHash stores already track as independent verifiable
+// versions into one typed, externally auditable event stream.
+type KEL struct {
+	hasher          cryptointerfaces.Hasher
+	eventRepository repository.Repository[*Event]
+	receipts        *receiptStore
+	witnesses       WitnessPool
+	threshold       int // m of the witness pool's n required before a rot/drt is established
+}
+
+// NewKEL constructs a KEL. witnesses may be nil, in which case establishing events are
+// accepted without receipts (suitable for tests or single-operator deployments).
+func NewKEL(store data.Store, witnesses WitnessPool, threshold int) *KEL {
+	return &KEL{
+		hasher:          crypto.NewBlake3(),
+		eventRepository: repository.NewVerifiableRepository[*Event](store, true, true, nil),
+		receipts:        newReceiptStore(store),
+		witnesses:       witnesses,
+		threshold:       threshold,
+	}
+}
+
+// Append records a new event for prefix, soliciting and requiring witness receipts first if
+// the event type establishes a new signing key.
+func (k *KEL) Append(ctx context.Context, eventType EventType, publicKey, rotationHash string) (*Event, error) {
+	event := &Event{
+		EventType:    eventType,
+		PublicKey:    publicKey,
+		RotationHash: rotationHash,
+	}
+
+	if err := k.eventRepository.CreateVersion(ctx, event); err != nil {
+		return nil, err
+	}
+
+	if eventType.establishing() && k.witnesses != nil {
+		collected, err := solicit(ctx, k.witnesses, k.receipts, event.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		if collected < k.threshold {
+			return nil, fmt.Errorf("insufficient witness receipts for %s: got %d, need %d", event.Id, collected, k.threshold)
+		}
+	}
+
+	return event, nil
+}
+
+// events returns every event for prefix in log order (inception first).
+func (k *KEL) events(ctx context.Context, prefix string) ([]*Event, error) {
+	events := []*Event{}
+
+	if err := k.eventRepository.ListLatestByPrefix(
+		ctx,
+		&events,
+		expressions.Equal("prefix", prefix),
+		nil,
+		orderings.Ascending("sequence_number"),
+		nil,
+	); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// Verify walks prefix's log from inception, checking that each rotation's RotationHash
+// commitment matches the next event's public key and that every establishing event cleared
+// its witness threshold. It does not re-verify signatures over the events themselves; that
+// is the verifiable-storage layer's job via algorithms.VerifyPrefixAndData/VerifyAddressAndData.
+func (k *KEL) Verify(ctx context.Context, prefix string) error {
+	events, err := k.events(ctx, prefix)
+	if err != nil {
+		return err
+	}
+
+	if len(events) == 0 {
+		return fmt.Errorf("empty key event log for %s", prefix)
+	}
+
+	if events[0].EventType != EventInception {
+		return fmt.Errorf("log for %s does not begin with an inception event", prefix)
+	}
+
+	lastRotationHash := ""
+	for i, event := range events {
+		if i > 0 {
+			previous := events[i-1]
+
+			if event.Previous == nil || *event.Previous != previous.Id {
+				return fmt.Errorf("broken chain at sequence %d for %s", event.SequenceNumber, prefix)
+			}
+
+			if !strings.EqualFold(k.hasher.Sum([]byte(event.PublicKey)), lastRotationHash) {
+				return fmt.Errorf("rotation hash commitment violated at sequence %d for %s", event.SequenceNumber, prefix)
+			}
+		}
+
+		if event.EventType.establishing() && k.witnesses != nil {
+			count, err := k.receipts.countFor(ctx, event.Id)
+			if err != nil {
+				return err
+			}
+
+			if count < k.threshold {
+				return fmt.Errorf("event %s never reached its witness threshold (%d/%d)", event.Id, count, k.threshold)
+			}
+		}
+
+		lastRotationHash = event.RotationHash
+	}
+
+	return nil
+}
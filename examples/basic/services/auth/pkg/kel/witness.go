@@ -0,0 +1,114 @@
+package kel
+
+import (
+	"context"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/expressions"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/repository"
+)
+
+const RECEIPTS_TABLE_SQL = `
+	CREATE TABLE IF NOT EXISTS kel_receipts (
+		-- Standard fields
+		id              	TEXT PRIMARY KEY,
+		prefix				TEXT NOT NULL,
+		previous        	TEXT,
+		sequence_number 	BIGINT NOT NULL,
+
+		-- Optional fields
+		created_at          TIMESTAMP NOT NULL,
+
+		-- Model-specific fields
+		event_said          TEXT NOT NULL,
+		witness_prefix      TEXT NOT NULL,
+		signature           TEXT NOT NULL,
+
+		-- Uniqueness constraint for sequence numbers
+		UNIQUE(prefix, sequence_number),
+
+		-- A witness may only receipt a given event once
+		UNIQUE(event_said, witness_prefix, sequence_number)
+	);
+`
+
+// Receipt is a single witness's signature over an event's SAID, keyed by (event_said,
+// witness_prefix) the way chunk0-3 describes.
+type Receipt struct {
+	primitives.VerifiableRecorder
+	EventSaid     string `db:"event_said" json:"eventSaid"`
+	WitnessPrefix string `db:"witness_prefix" json:"witnessPrefix"`
+	Signature     string `db:"signature" json:"signature"`
+}
+
+func (*Receipt) TableName() string {
+	return "kel_receipts"
+}
+
+// WitnessPool asks a configured set of witnesses to countersign an event's SAID.
+type WitnessPool interface {
+	// Witnesses returns the full set of witness identity prefixes this pool can solicit.
+	Witnesses() []string
+
+	// Receipt asks one witness to countersign the event SAID, returning its signature.
+	Receipt(ctx context.Context, witnessPrefix, eventSaid string) (string, error)
+}
+
+type receiptStore struct {
+	repository repository.Repository[*Receipt]
+}
+
+func newReceiptStore(store data.Store) *receiptStore {
+	return &receiptStore{
+		repository: repository.NewVerifiableRepository[*Receipt](store, true, true, nil),
+	}
+}
+
+func (s *receiptStore) record(ctx context.Context, eventSaid, witnessPrefix, signature string) error {
+	receipt := &Receipt{
+		EventSaid:     eventSaid,
+		WitnessPrefix: witnessPrefix,
+		Signature:     signature,
+	}
+
+	return s.repository.CreateVersion(ctx, receipt)
+}
+
+func (s *receiptStore) countFor(ctx context.Context, eventSaid string) (int, error) {
+	receipts := []*Receipt{}
+
+	if err := s.repository.ListLatestByPrefix(
+		ctx,
+		&receipts,
+		expressions.Equal("event_said", eventSaid),
+		nil,
+		nil,
+		nil,
+	); err != nil {
+		return 0, err
+	}
+
+	return len(receipts), nil
+}
+
+// solicit fans an event SAID out to every witness in the pool and records whichever receipts
+// come back, returning the number collected.
+func solicit(ctx context.Context, pool WitnessPool, receipts *receiptStore, eventSaid string) (int, error) {
+	collected := 0
+
+	for _, witnessPrefix := range pool.Witnesses() {
+		signature, err := pool.Receipt(ctx, witnessPrefix, eventSaid)
+		if err != nil {
+			continue
+		}
+
+		if err := receipts.record(ctx, eventSaid, witnessPrefix, signature); err != nil {
+			return collected, err
+		}
+
+		collected++
+	}
+
+	return collected, nil
+}
@@ -17,6 +17,8 @@ const AUTHENTICATION_NONCE_TABLE_SQL = `
 		identity 			TEXT NOT NULL,
 		challenge_nonce		TEXT NOT NULL,
 		used                BOOLEAN NOT NULL,
+		expired             BOOLEAN NOT NULL DEFAULT false,
+		client_ip           TEXT,
 
 		-- Uniqueness constraint for sequence numbers
 		UNIQUE(prefix, sequence_number),
@@ -31,6 +33,15 @@ type AuthenticationNonce struct {
 	Identity       string `db:"identity" json:"identity"`
 	ChallengeNonce string `db:"challenge_nonce" json:"challengeNonce"`
 	Used           bool   `db:"used" json:"used"`
+
+	// Expired is set by the background sweeper once a nonce has aged past its lifetime,
+	// so Generate's rate-limit count doesn't have to re-check created_at against lifetime
+	// for nonces the sweeper just hasn't gotten to yet.
+	Expired bool `db:"expired" json:"expired"`
+
+	// ClientIP is optional: populated when the caller threads a client IP through context,
+	// to additionally bound Generate by source IP rather than only by identity.
+	ClientIP string `db:"client_ip" json:"clientIp,omitempty"`
 }
 
 func (*AuthenticationNonce) TableName() string {
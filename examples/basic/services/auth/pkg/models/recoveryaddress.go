@@ -0,0 +1,50 @@
+package models
+
+import "github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
+
+const RECOVERY_ADDRESS_TABLE_SQL = `
+	CREATE TABLE IF NOT EXISTS recoveryaddresses (
+		-- Standard fields
+		id              	TEXT PRIMARY KEY,
+		prefix				TEXT NOT NULL,
+		previous        	TEXT,
+		sequence_number 	BIGINT NOT NULL,
+
+		-- Optional fields
+		created_at          TIMESTAMP NOT NULL,
+
+		-- Model-specific fields
+		identity 			TEXT NOT NULL,
+		via 				TEXT NOT NULL,
+		value 				TEXT NOT NULL,
+		hash     			TEXT NOT NULL,
+		verified            BOOLEAN NOT NULL DEFAULT false,
+		removed             BOOLEAN NOT NULL DEFAULT false,
+
+		-- Uniqueness constraint for sequence numbers
+		UNIQUE(prefix, sequence_number),
+
+		-- Uniqueness constraint for one chain per (identity, via, value)
+		UNIQUE(identity, via, value, sequence_number)
+	);
+`
+
+// RecoveryAddress is one recovery channel registered against an identity (e.g. an email
+// address, a phone number, a backup-code set) alongside the single recovery hash
+// RecoveryHash already models. Hash is a pre-committed hash of the value that proves
+// ownership of this channel: Verify checks a caller-supplied token against it, and
+// RotateHash replaces it the same way RecoveryHash.Rotate replaces the account-wide
+// recovery hash.
+type RecoveryAddress struct {
+	primitives.VerifiableRecorder
+	Identity string `db:"identity" json:"identity"`
+	Via      string `db:"via" json:"via"`
+	Value    string `db:"value" json:"value"`
+	Hash     string `db:"hash" json:"hash"`
+	Verified bool   `db:"verified" json:"verified"`
+	Removed  bool   `db:"removed" json:"removed"`
+}
+
+func (*RecoveryAddress) TableName() string {
+	return "recoveryaddresses"
+}
@@ -16,6 +16,7 @@ const RECOVERY_HASH_TABLE_SQL = `
 		-- Model-specific fields
 		identity 			TEXT NOT NULL,
 		recovery_hash 		TEXT NOT NULL,
+		rotation_hash       TEXT NOT NULL,
 
 		-- Uniqueness constraint for sequence numbers
 		UNIQUE(prefix, sequence_number),
@@ -29,6 +30,10 @@ type RecoveryHash struct {
 	primitives.VerifiableRecorder
 	Identity     string `db:"identity" json:"identity"`
 	RecoveryHash string `db:"recovery_hash" json:"recovery_hash"`
+	// RotationHash is a pre-committed hash of the next recovery hash, the same discipline
+	// AuthenticationKeys.RotationHash enforces for device keys: Rotate must be handed a
+	// value whose hash matches this before it's accepted.
+	RotationHash string `db:"rotation_hash" json:"rotation_hash"`
 }
 
 func (*RecoveryHash) TableName() string {
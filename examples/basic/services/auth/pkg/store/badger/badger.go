@@ -0,0 +1,571 @@
+// Package badger provides a store.Backend backed by an embedded BadgerDB, registered under
+// the "badger" DSN scheme (e.g. "badger:///var/lib/better-auth/auth.db"), for deployments
+// that want durable storage without standing up a separate database.
+package badger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+	betterauthcrypto "github.com/jasoncolburne/better-auth-go/examples/crypto"
+	"github.com/jasoncolburne/better-auth-go/pkg/cryptointerfaces"
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/cryptosuite"
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/store"
+)
+
+const (
+	identityKeyPrefix        = "identity/"
+	deviceKeyPrefix          = "device/"
+	nonceKeyPrefix           = "nonce/"
+	recoveryKeyPrefix        = "recovery/"
+	recoveryAddressKeyPrefix = "recoveryaddress/"
+)
+
+type identityRecord struct {
+	Deleted bool `json:"deleted"`
+}
+
+type deviceRecord struct {
+	PublicKey    string `json:"publicKey"`
+	RotationHash string `json:"rotationHash"`
+	Revoked      bool   `json:"revoked"`
+}
+
+type recoveryHashRecord struct {
+	Hash         string `json:"hash"`
+	RotationHash string `json:"rotationHash"`
+}
+
+type recoveryAddressRecord struct {
+	Hash     string `json:"hash"`
+	Verified bool   `json:"verified"`
+}
+
+type nonceRecord struct {
+	Identity  string    `json:"identity"`
+	Used      bool      `json:"used"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func deviceKey(identity, device string) string {
+	return fmt.Sprintf("%s%s/%s", deviceKeyPrefix, identity, device)
+}
+
+func recoveryAddressKey(identity, via, value string) string {
+	return fmt.Sprintf("%s%s/%s/%s", recoveryAddressKeyPrefix, identity, via, value)
+}
+
+func get[T any](db *badger.DB, key string, value *T) error {
+	return db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(key))
+		if err != nil {
+			return err
+		}
+
+		return item.Value(func(raw []byte) error {
+			return json.Unmarshal(raw, value)
+		})
+	})
+}
+
+func put[T any](db *badger.DB, key string, value T) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return db.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(key), raw)
+	})
+}
+
+// IdentityStore is a BadgerDB-backed store.IdentityStore.
+type IdentityStore struct {
+	db *badger.DB
+}
+
+func NewIdentityStore(db *badger.DB) *IdentityStore {
+	return &IdentityStore{db: db}
+}
+
+func (s *IdentityStore) Exists(ctx context.Context, identity string) (bool, error) {
+	record := &identityRecord{}
+	if err := get(s.db, identityKeyPrefix+identity, record); err != nil {
+		if err == badger.ErrKeyNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if record.Deleted {
+		return false, fmt.Errorf("account deleted")
+	}
+
+	return true, nil
+}
+
+func (s *IdentityStore) Delete(ctx context.Context, identity string) error {
+	record := &identityRecord{}
+	if err := get(s.db, identityKeyPrefix+identity, record); err != nil {
+		return err
+	}
+
+	record.Deleted = true
+
+	return put(s.db, identityKeyPrefix+identity, record)
+}
+
+// AuthenticationKeyStore is a BadgerDB-backed store.AuthenticationKeyStore.
+type AuthenticationKeyStore struct {
+	db       *badger.DB
+	registry *cryptosuite.Registry
+}
+
+func NewAuthenticationKeyStore(db *badger.DB) *AuthenticationKeyStore {
+	return &AuthenticationKeyStore{
+		db:       db,
+		registry: cryptosuite.Default,
+	}
+}
+
+func (s *AuthenticationKeyStore) Register(ctx context.Context, identity, device, publicKey, rotationHash string, existingIdentity bool) error {
+	identityRecord := &identityRecord{}
+	err := get(s.db, identityKeyPrefix+identity, identityRecord)
+
+	switch {
+	case err == nil:
+		if !existingIdentity {
+			return fmt.Errorf("identity already exists")
+		}
+	case err == badger.ErrKeyNotFound:
+		if existingIdentity {
+			return fmt.Errorf("identity does not exist")
+		}
+
+		if err := put(s.db, identityKeyPrefix+identity, &identityRecord{}); err != nil {
+			return err
+		}
+	default:
+		return err
+	}
+
+	key := deviceKey(identity, device)
+
+	existing := &deviceRecord{}
+	if err := get(s.db, key, existing); err == nil {
+		return fmt.Errorf("device already registered")
+	} else if err != badger.ErrKeyNotFound {
+		return err
+	}
+
+	return put(s.db, key, &deviceRecord{PublicKey: publicKey, RotationHash: rotationHash})
+}
+
+func (s *AuthenticationKeyStore) Rotate(ctx context.Context, identity, device, publicKey, rotationHash string) error {
+	key := deviceKey(identity, device)
+
+	record := &deviceRecord{}
+	if err := get(s.db, key, record); err != nil {
+		return err
+	}
+
+	if record.Revoked {
+		return fmt.Errorf("revoked device")
+	}
+
+	suite := s.registry.Lookup(record.RotationHash)
+	hash := suite.TagValue(suite.Hasher.Sum([]byte(publicKey)))
+
+	if !strings.EqualFold(hash, record.RotationHash) {
+		return fmt.Errorf("rotation hash does not match")
+	}
+
+	record.PublicKey = publicKey
+	record.RotationHash = rotationHash
+
+	return put(s.db, key, record)
+}
+
+func (s *AuthenticationKeyStore) Public(ctx context.Context, identity, device string) (string, error) {
+	record := &deviceRecord{}
+	if err := get(s.db, deviceKey(identity, device), record); err != nil {
+		return "", err
+	}
+
+	if record.Revoked {
+		return "", fmt.Errorf("revoked device")
+	}
+
+	return record.PublicKey, nil
+}
+
+func (s *AuthenticationKeyStore) RevokeDevice(ctx context.Context, identity, device string) error {
+	key := deviceKey(identity, device)
+
+	record := &deviceRecord{}
+	if err := get(s.db, key, record); err != nil {
+		return err
+	}
+
+	record.Revoked = true
+
+	return put(s.db, key, record)
+}
+
+func (s *AuthenticationKeyStore) RevokeDevices(ctx context.Context, identity string) error {
+	prefix := []byte(fmt.Sprintf("%s%s/", deviceKeyPrefix, identity))
+
+	return s.db.Update(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			item := it.Item()
+
+			record := &deviceRecord{}
+			if err := item.Value(func(raw []byte) error {
+				return json.Unmarshal(raw, record)
+			}); err != nil {
+				return err
+			}
+
+			record.Revoked = true
+
+			raw, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+
+			if err := txn.Set(item.KeyCopy(nil), raw); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *AuthenticationKeyStore) DeleteIdentity(ctx context.Context, identity string) error {
+	if err := s.RevokeDevices(ctx, identity); err != nil {
+		return err
+	}
+
+	record := &identityRecord{}
+	if err := get(s.db, identityKeyPrefix+identity, record); err != nil {
+		return err
+	}
+
+	record.Deleted = true
+
+	return put(s.db, identityKeyPrefix+identity, record)
+}
+
+func (s *AuthenticationKeyStore) EnsureActive(ctx context.Context, identity, device string) error {
+	identityRecord := &identityRecord{}
+	if err := get(s.db, identityKeyPrefix+identity, identityRecord); err != nil {
+		return err
+	}
+
+	if identityRecord.Deleted {
+		return fmt.Errorf("identity deleted")
+	}
+
+	deviceRecord := &deviceRecord{}
+	if err := get(s.db, deviceKey(identity, device), deviceRecord); err != nil {
+		return err
+	}
+
+	if deviceRecord.Revoked {
+		return fmt.Errorf("device revoked")
+	}
+
+	return nil
+}
+
+// NonceStore is a BadgerDB-backed store.NonceStore.
+type NonceStore struct {
+	db       *badger.DB
+	noncer   cryptointerfaces.Noncer
+	lifetime time.Duration
+}
+
+func NewNonceStore(db *badger.DB, lifetime time.Duration) *NonceStore {
+	return &NonceStore{
+		db:       db,
+		noncer:   betterauthcrypto.NewNoncer(),
+		lifetime: lifetime,
+	}
+}
+
+func (s *NonceStore) Generate(ctx context.Context, identity string) (string, error) {
+	nonce, err := s.noncer.Generate128()
+	if err != nil {
+		return "", err
+	}
+
+	record := &nonceRecord{Identity: identity, CreatedAt: time.Now()}
+
+	if err := put(s.db, nonceKeyPrefix+nonce, record); err != nil {
+		return "", err
+	}
+
+	return nonce, nil
+}
+
+func (s *NonceStore) Verify(ctx context.Context, nonce string) (string, error) {
+	key := nonceKeyPrefix + nonce
+
+	record := &nonceRecord{}
+	if err := get(s.db, key, record); err != nil {
+		return "", err
+	}
+
+	if record.Used {
+		return "", fmt.Errorf("challenge already used")
+	}
+
+	if record.CreatedAt.Add(s.lifetime).Before(time.Now()) {
+		return "", fmt.Errorf("challenge expired")
+	}
+
+	record.Used = true
+
+	if err := put(s.db, key, record); err != nil {
+		return "", err
+	}
+
+	return record.Identity, nil
+}
+
+// RecoveryHashStore is a BadgerDB-backed store.RecoveryHashStore.
+type RecoveryHashStore struct {
+	db     *badger.DB
+	hasher cryptointerfaces.Hasher
+}
+
+func NewRecoveryHashStore(db *badger.DB) *RecoveryHashStore {
+	return &RecoveryHashStore{
+		db:     db,
+		hasher: betterauthcrypto.NewBlake3(),
+	}
+}
+
+func (s *RecoveryHashStore) Register(ctx context.Context, identity, recoveryHash, commitment string) error {
+	key := recoveryKeyPrefix + identity
+
+	existing := &recoveryHashRecord{}
+	if err := get(s.db, key, existing); err == nil {
+		return fmt.Errorf("recovery hash already registered")
+	} else if err != badger.ErrKeyNotFound {
+		return err
+	}
+
+	return put(s.db, key, &recoveryHashRecord{Hash: recoveryHash, RotationHash: commitment})
+}
+
+func (s *RecoveryHashStore) Rotate(ctx context.Context, identity, newHash, commitment string) error {
+	key := recoveryKeyPrefix + identity
+
+	record := &recoveryHashRecord{}
+	if err := get(s.db, key, record); err != nil {
+		return err
+	}
+
+	hash := s.hasher.Sum([]byte(newHash))
+
+	if !strings.EqualFold(hash, record.RotationHash) {
+		return fmt.Errorf("rotation hash does not match")
+	}
+
+	record.Hash = newHash
+	record.RotationHash = commitment
+
+	return put(s.db, key, record)
+}
+
+func (s *RecoveryHashStore) Change(ctx context.Context, identity, newHash string) error {
+	key := recoveryKeyPrefix + identity
+
+	record := &recoveryHashRecord{}
+	if err := get(s.db, key, record); err != nil {
+		return err
+	}
+
+	record.Hash = newHash
+
+	return put(s.db, key, record)
+}
+
+// RecoveryAddressStore is a BadgerDB-backed store.RecoveryAddressStore.
+type RecoveryAddressStore struct {
+	db     *badger.DB
+	hasher cryptointerfaces.Hasher
+}
+
+func NewRecoveryAddressStore(db *badger.DB) *RecoveryAddressStore {
+	return &RecoveryAddressStore{
+		db:     db,
+		hasher: betterauthcrypto.NewBlake3(),
+	}
+}
+
+func (s *RecoveryAddressStore) Add(ctx context.Context, identity, via, value, hash string) error {
+	key := recoveryAddressKey(identity, via, value)
+
+	existing := &recoveryAddressRecord{}
+	if err := get(s.db, key, existing); err == nil {
+		return fmt.Errorf("recovery address already registered")
+	} else if err != badger.ErrKeyNotFound {
+		return err
+	}
+
+	return put(s.db, key, &recoveryAddressRecord{Hash: hash})
+}
+
+func (s *RecoveryAddressStore) Verify(ctx context.Context, identity, via, token string) error {
+	prefix := []byte(fmt.Sprintf("%s%s/%s/", recoveryAddressKeyPrefix, identity, via))
+	hash := s.hasher.Sum([]byte(token))
+
+	var matchedKey []byte
+	record := &recoveryAddressRecord{}
+
+	err := s.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			item := it.Item()
+
+			candidate := &recoveryAddressRecord{}
+			if err := item.Value(func(raw []byte) error {
+				return json.Unmarshal(raw, candidate)
+			}); err != nil {
+				return err
+			}
+
+			if strings.EqualFold(hash, candidate.Hash) {
+				matchedKey = item.KeyCopy(nil)
+				record = candidate
+				return nil
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if matchedKey == nil {
+		return fmt.Errorf("no recovery address for %s/%s matches the supplied token", identity, via)
+	}
+
+	record.Verified = true
+
+	return put(s.db, string(matchedKey), record)
+}
+
+func (s *RecoveryAddressStore) List(ctx context.Context, identity string) ([]store.RecoveryAddress, error) {
+	prefix := []byte(fmt.Sprintf("%s%s/", recoveryAddressKeyPrefix, identity))
+
+	var addresses []store.RecoveryAddress
+
+	err := s.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			item := it.Item()
+
+			rest := strings.TrimPrefix(string(item.Key()), string(prefix))
+			parts := strings.SplitN(rest, "/", 2)
+			if len(parts) != 2 {
+				return fmt.Errorf("malformed recovery address key %q", item.Key())
+			}
+
+			record := &recoveryAddressRecord{}
+			if err := item.Value(func(raw []byte) error {
+				return json.Unmarshal(raw, record)
+			}); err != nil {
+				return err
+			}
+
+			addresses = append(addresses, store.RecoveryAddress{
+				Via:      parts[0],
+				Value:    parts[1],
+				Verified: record.Verified,
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return addresses, nil
+}
+
+func (s *RecoveryAddressStore) Remove(ctx context.Context, identity, via, value string) error {
+	key := recoveryAddressKey(identity, via, value)
+
+	if err := get(s.db, key, &recoveryAddressRecord{}); err != nil {
+		return err
+	}
+
+	return s.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete([]byte(key))
+	})
+}
+
+func (s *RecoveryAddressStore) RotateHash(ctx context.Context, identity, via, value, newHash, commitment string) error {
+	key := recoveryAddressKey(identity, via, value)
+
+	record := &recoveryAddressRecord{}
+	if err := get(s.db, key, record); err != nil {
+		return err
+	}
+
+	hash := s.hasher.Sum([]byte(newHash))
+
+	if !strings.EqualFold(hash, record.Hash) {
+		return fmt.Errorf("rotation hash does not match")
+	}
+
+	record.Hash = commitment
+
+	return put(s.db, key, record)
+}
+
+// Backend opens a BadgerDB-backed Stores bundle. It registers itself under the "badger" DSN
+// scheme; the DSN's path names the database directory, e.g. "badger:///var/lib/auth.db".
+type Backend struct{}
+
+func NewBackend() *Backend {
+	return &Backend{}
+}
+
+func (Backend) Open(ctx context.Context, opts store.Options) (*store.Stores, error) {
+	parsed, err := url.Parse(opts.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("invalid badger dsn %q: %w", opts.DSN, err)
+	}
+
+	db, err := badger.Open(badger.DefaultOptions(parsed.Path))
+	if err != nil {
+		return nil, err
+	}
+
+	return &store.Stores{
+		Identity:          NewIdentityStore(db),
+		AuthenticationKey: NewAuthenticationKeyStore(db),
+		Nonce:             NewNonceStore(db, opts.NonceLifetime),
+		RecoveryHash:      NewRecoveryHashStore(db),
+		RecoveryAddress:   NewRecoveryAddressStore(db),
+	}, nil
+}
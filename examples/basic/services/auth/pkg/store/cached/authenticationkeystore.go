@@ -0,0 +1,154 @@
+// Package cached wraps a store.AuthenticationKeyStore with a cache.Cache in front of its hot
+// Public() lookups, the way neo-go's MemCachedStore sits in front of a slower backing store.
+// Reads consult the cache first and fall through to the wrapped store on a miss; every
+// mutating call invalidates or writes through the keys it touched, so the same wrapper works
+// unchanged whether the backend is Postgres, SQLite, or an in-memory store.
+package cached
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/cache"
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/store"
+)
+
+// negativePrefix marks a cached value as a stand-in for an error Public() returned (e.g.
+// "revoked device", "identity deleted") rather than a public key, so a cache hit can
+// reconstruct that error without round-tripping the backend again.
+const negativePrefix = "\x00err:"
+
+// Metrics is a point-in-time snapshot of AuthenticationKeyStore's Public() hit/miss counters.
+type Metrics struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// AuthenticationKeyStore wraps a store.AuthenticationKeyStore with a cache.Cache. ttl bounds
+// how long a confirmed public key is trusted from cache; negativeTTL bounds how long a
+// Public() error (revoked device, deleted identity, not found) is cached, kept short so a
+// corrected record isn't masked for long.
+type AuthenticationKeyStore struct {
+	backend store.AuthenticationKeyStore
+	cache   cache.Cache
+
+	ttl         time.Duration
+	negativeTTL time.Duration
+
+	hits, misses uint64 // atomic
+}
+
+func NewAuthenticationKeyStore(backend store.AuthenticationKeyStore, c cache.Cache, ttl, negativeTTL time.Duration) *AuthenticationKeyStore {
+	return &AuthenticationKeyStore{
+		backend:     backend,
+		cache:       c,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+	}
+}
+
+// Metrics returns a snapshot of this store's Public() hit/miss counters.
+func (s *AuthenticationKeyStore) Metrics() Metrics {
+	return Metrics{
+		Hits:   atomic.LoadUint64(&s.hits),
+		Misses: atomic.LoadUint64(&s.misses),
+	}
+}
+
+func deviceKey(identity, device string) string {
+	return identity + "/" + device
+}
+
+func identityPrefix(identity string) string {
+	return identity + "/"
+}
+
+func (s *AuthenticationKeyStore) Register(ctx context.Context, identity, device, publicKey, rotationHash string, existingIdentity bool) error {
+	if err := s.backend.Register(ctx, identity, device, publicKey, rotationHash, existingIdentity); err != nil {
+		return err
+	}
+
+	return s.cache.Set(ctx, deviceKey(identity, device), publicKey, s.ttl)
+}
+
+func (s *AuthenticationKeyStore) Rotate(ctx context.Context, identity, device, publicKey, rotationHash string) error {
+	if err := s.backend.Rotate(ctx, identity, device, publicKey, rotationHash); err != nil {
+		return err
+	}
+
+	return s.cache.Set(ctx, deviceKey(identity, device), publicKey, s.ttl)
+}
+
+func (s *AuthenticationKeyStore) Public(ctx context.Context, identity, device string) (string, error) {
+	key := deviceKey(identity, device)
+
+	if cached, ok, err := s.cache.Get(ctx, key); err == nil && ok {
+		atomic.AddUint64(&s.hits, 1)
+
+		if msg, isNegative := cutPrefix(cached, negativePrefix); isNegative {
+			return "", errString(msg)
+		}
+
+		return cached, nil
+	}
+
+	atomic.AddUint64(&s.misses, 1)
+
+	publicKey, err := s.backend.Public(ctx, identity, device)
+	if err != nil {
+		s.cache.Set(ctx, key, negativePrefix+err.Error(), s.negativeTTL)
+		return "", err
+	}
+
+	s.cache.Set(ctx, key, publicKey, s.ttl)
+
+	return publicKey, nil
+}
+
+func (s *AuthenticationKeyStore) RevokeDevice(ctx context.Context, identity, device string) error {
+	if err := s.backend.RevokeDevice(ctx, identity, device); err != nil {
+		return err
+	}
+
+	return s.cache.Delete(ctx, deviceKey(identity, device))
+}
+
+func (s *AuthenticationKeyStore) RevokeDevices(ctx context.Context, identity string) error {
+	if err := s.backend.RevokeDevices(ctx, identity); err != nil {
+		return err
+	}
+
+	return s.cache.Invalidate(ctx, identityPrefix(identity))
+}
+
+func (s *AuthenticationKeyStore) DeleteIdentity(ctx context.Context, identity string) error {
+	if err := s.backend.DeleteIdentity(ctx, identity); err != nil {
+		return err
+	}
+
+	return s.cache.Invalidate(ctx, identityPrefix(identity))
+}
+
+// EnsureActive isn't on the Public() hot path this cache targets, and duplicating its
+// identity-deleted/device-revoked logic against the cache would risk drifting from the
+// backend's own rules, so it passes straight through.
+func (s *AuthenticationKeyStore) EnsureActive(ctx context.Context, identity, device string) error {
+	return s.backend.EnsureActive(ctx, identity, device)
+}
+
+// cutPrefix reports whether s has prefix and, if so, the remainder, without pulling in
+// strings just for this one call site's worth of use.
+func cutPrefix(s, prefix string) (string, bool) {
+	if len(s) < len(prefix) || s[:len(prefix)] != prefix {
+		return "", false
+	}
+
+	return s[len(prefix):], true
+}
+
+// errString is a bare string turned back into an error, for replaying a cached Public()
+// failure's message without needing the original error's concrete type.
+type errString string
+
+func (e errString) Error() string { return string(e) }
@@ -0,0 +1,166 @@
+// Package conformance exercises a store.Backend against the behavior every implementation
+// (memory, badger, verifiablesql, sqlite) is expected to honor, the way dex's
+// storage/conformance package does for its own pluggable backends. It is plain, callable
+// Go rather than a *_test.go file so it can be imported both by ad hoc verification tools
+// and, should the repo ever grow a test suite, by table-driven tests for each backend.
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jasoncolburne/better-auth-go/examples/crypto"
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/store"
+)
+
+// Config bounds how long Run is willing to wait for a nonce to expire. Backends that sweep
+// expired nonces in the background (e.g. verifiablesql.NonceStore) need real wall-clock time
+// to pass, since none of them accept an injected clock; Run sleeps at most NonceLifetime+Settle
+// before asserting a nonce issued at the start of the run has expired.
+type Config struct {
+	// NonceLifetime is passed through to opts when opening the Stores under test; keep it
+	// short (e.g. 50ms) so the expiry check doesn't make Run slow.
+	NonceLifetime time.Duration
+
+	// Settle is added on top of NonceLifetime before Run checks that an old nonce has
+	// expired, to absorb a backend's sweep interval.
+	Settle time.Duration
+}
+
+// Run opens a fresh Stores bundle from backend and exercises register/rotate/revoke
+// lifecycles, nonce issuance/verification/replay/expiry, and recovery-hash rotation against
+// it, returning every assertion failure it finds rather than stopping at the first one, so a
+// single run surfaces the full list of ways a backend diverges from the contract.
+func Run(ctx context.Context, backend store.Backend, opts store.Options, cfg Config) []error {
+	var errs []error
+
+	check := func(name string, err error) bool {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			return false
+		}
+		return true
+	}
+
+	expect := func(name string, got error, wantErr bool) {
+		if wantErr && got == nil {
+			errs = append(errs, fmt.Errorf("%s: expected an error, got nil", name))
+		} else if !wantErr && got != nil {
+			errs = append(errs, fmt.Errorf("%s: unexpected error: %w", name, got))
+		}
+	}
+
+	opts.NonceLifetime = cfg.NonceLifetime
+
+	stores, err := backend.Open(ctx, opts)
+	if !check("open", err) {
+		return errs
+	}
+
+	hasher := crypto.NewBlake3()
+
+	const (
+		identity   = "conformance-identity"
+		device     = "conformance-device"
+		publicKey1 = "conformance-public-key-1"
+		publicKey2 = "conformance-public-key-2"
+		publicKey3 = "conformance-public-key-3"
+	)
+
+	commitment1 := hasher.Sum([]byte(publicKey2))
+	commitment2 := hasher.Sum([]byte(publicKey3))
+
+	exists, err := stores.Identity.Exists(ctx, identity)
+	if check("identity.Exists(before register)", err) && exists {
+		errs = append(errs, fmt.Errorf("identity.Exists(before register): expected false, got true"))
+	}
+
+	check("authenticationKey.Register", stores.AuthenticationKey.Register(ctx, identity, device, publicKey1, commitment1, false))
+
+	expect("authenticationKey.Register(duplicate identity)",
+		stores.AuthenticationKey.Register(ctx, identity, device, publicKey1, commitment1, false), true)
+
+	check("authenticationKey.EnsureActive(after register)", stores.AuthenticationKey.EnsureActive(ctx, identity, device))
+
+	got, err := stores.AuthenticationKey.Public(ctx, identity, device)
+	if check("authenticationKey.Public", err) && got != publicKey1 {
+		errs = append(errs, fmt.Errorf("authenticationKey.Public: got %q, want %q", got, publicKey1))
+	}
+
+	expect("authenticationKey.Rotate(wrong rotation hash)",
+		stores.AuthenticationKey.Rotate(ctx, identity, device, publicKey3, commitment2), true)
+
+	check("authenticationKey.Rotate", stores.AuthenticationKey.Rotate(ctx, identity, device, publicKey2, commitment2))
+
+	got, err = stores.AuthenticationKey.Public(ctx, identity, device)
+	if check("authenticationKey.Public(after rotate)", err) && got != publicKey2 {
+		errs = append(errs, fmt.Errorf("authenticationKey.Public(after rotate): got %q, want %q", got, publicKey2))
+	}
+
+	check("authenticationKey.RevokeDevice", stores.AuthenticationKey.RevokeDevice(ctx, identity, device))
+	expect("authenticationKey.EnsureActive(after revoke)", stores.AuthenticationKey.EnsureActive(ctx, identity, device), true)
+
+	const secondDevice = "conformance-device-2"
+	check("authenticationKey.Register(second device)",
+		stores.AuthenticationKey.Register(ctx, identity, secondDevice, publicKey1, commitment1, true))
+	check("authenticationKey.RevokeDevices", stores.AuthenticationKey.RevokeDevices(ctx, identity))
+	expect("authenticationKey.EnsureActive(after RevokeDevices)", stores.AuthenticationKey.EnsureActive(ctx, identity, secondDevice), true)
+
+	check("authenticationKey.DeleteIdentity", stores.AuthenticationKey.DeleteIdentity(ctx, identity))
+	expect("authenticationKey.Register(deleted identity, existingIdentity=true)",
+		stores.AuthenticationKey.Register(ctx, identity, device, publicKey1, commitment1, true), true)
+
+	exists, err = stores.Identity.Exists(ctx, identity)
+	expect("identity.Exists(deleted identity)", err, true)
+	if exists {
+		errs = append(errs, fmt.Errorf("identity.Exists(deleted identity): expected false, got true"))
+	}
+
+	nonce, err := stores.Nonce.Generate(ctx, identity)
+	if check("nonce.Generate", err) {
+		verified, err := stores.Nonce.Verify(ctx, nonce)
+		if check("nonce.Verify", err) && verified != identity {
+			errs = append(errs, fmt.Errorf("nonce.Verify: got identity %q, want %q", verified, identity))
+		}
+
+		expect("nonce.Verify(replay)", func() error {
+			_, err := stores.Nonce.Verify(ctx, nonce)
+			return err
+		}(), true)
+	}
+
+	if cfg.NonceLifetime > 0 {
+		staleNonce, err := stores.Nonce.Generate(ctx, identity)
+		if check("nonce.Generate(for expiry check)", err) {
+			time.Sleep(cfg.NonceLifetime + cfg.Settle)
+
+			expect("nonce.Verify(expired)", func() error {
+				_, err := stores.Nonce.Verify(ctx, staleNonce)
+				return err
+			}(), true)
+		}
+	}
+
+	const (
+		recoveryIdentity = "conformance-recovery-identity"
+		recoveryHash1    = "conformance-recovery-hash-1"
+		recoveryHash2    = "conformance-recovery-hash-2"
+		recoveryHash3    = "conformance-recovery-hash-3"
+	)
+
+	recoveryCommitment1 := hasher.Sum([]byte(recoveryHash2))
+	recoveryCommitment2 := hasher.Sum([]byte(recoveryHash3))
+
+	check("recoveryHash.Register", stores.RecoveryHash.Register(ctx, recoveryIdentity, recoveryHash1, recoveryCommitment1))
+	expect("recoveryHash.Register(duplicate)",
+		stores.RecoveryHash.Register(ctx, recoveryIdentity, recoveryHash1, recoveryCommitment1), true)
+
+	expect("recoveryHash.Rotate(wrong commitment)",
+		stores.RecoveryHash.Rotate(ctx, recoveryIdentity, recoveryHash3, recoveryCommitment2), true)
+
+	check("recoveryHash.Rotate", stores.RecoveryHash.Rotate(ctx, recoveryIdentity, recoveryHash2, recoveryCommitment2))
+	check("recoveryHash.Change", stores.RecoveryHash.Change(ctx, recoveryIdentity, recoveryHash3))
+
+	return errs
+}
@@ -0,0 +1,34 @@
+// Package dial wires every known store.Backend into a store.Registry so callers can open a
+// Stores bundle from a single DSN without importing each backend package by hand. It lives
+// separately from pkg/store itself so that package can stay free of any particular backend's
+// dependencies.
+package dial
+
+import (
+	"context"
+
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/store"
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/store/badger"
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/store/memory"
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/store/sqlite"
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/store/verifiablesql"
+)
+
+var registry = newRegistry()
+
+func newRegistry() *store.Registry {
+	registry := store.NewRegistry()
+
+	registry.Register("memory", memory.NewBackend())
+	registry.Register("postgres", verifiablesql.NewBackend())
+	registry.Register("badger", badger.NewBackend())
+	registry.Register("sqlite", sqlite.NewBackend())
+
+	return registry
+}
+
+// Open opens a Stores bundle for dsn (e.g. "memory://", "postgres://...", "badger:///path",
+// "sqlite:///path").
+func Open(ctx context.Context, dsn string, opts store.Options) (*store.Stores, error) {
+	return registry.Open(ctx, dsn, opts)
+}
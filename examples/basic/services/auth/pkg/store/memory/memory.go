@@ -0,0 +1,510 @@
+// Package memory provides a map-backed store.Backend with no external dependencies,
+// registered under the "memory" DSN scheme, for use in tests.
+package memory
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jasoncolburne/better-auth-go/examples/crypto"
+	"github.com/jasoncolburne/better-auth-go/pkg/cryptointerfaces"
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/cryptosuite"
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/store"
+)
+
+type identityRecord struct {
+	deleted bool
+}
+
+// IdentityStore is an in-memory store.IdentityStore.
+type IdentityStore struct {
+	mu         sync.Mutex
+	identities map[string]*identityRecord
+}
+
+func NewIdentityStore() *IdentityStore {
+	return &IdentityStore{identities: map[string]*identityRecord{}}
+}
+
+func (s *IdentityStore) Exists(ctx context.Context, identity string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.identities[identity]
+	if !ok {
+		return false, nil
+	}
+
+	if record.deleted {
+		return false, fmt.Errorf("account deleted")
+	}
+
+	return true, nil
+}
+
+func (s *IdentityStore) Delete(ctx context.Context, identity string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.identities[identity]
+	if !ok {
+		return fmt.Errorf("identity not found")
+	}
+
+	record.deleted = true
+
+	return nil
+}
+
+type deviceKeyRecord struct {
+	publicKey    string
+	rotationHash string
+	revoked      bool
+}
+
+// AuthenticationKeyStore is an in-memory store.AuthenticationKeyStore.
+type AuthenticationKeyStore struct {
+	registry *cryptosuite.Registry
+
+	mu         sync.Mutex
+	identities map[string]*identityRecord
+	devices    map[string]map[string]*deviceKeyRecord // identity -> device -> record
+}
+
+func NewAuthenticationKeyStore() *AuthenticationKeyStore {
+	return &AuthenticationKeyStore{
+		registry:   cryptosuite.Default,
+		identities: map[string]*identityRecord{},
+		devices:    map[string]map[string]*deviceKeyRecord{},
+	}
+}
+
+func (s *AuthenticationKeyStore) Register(ctx context.Context, identity, device, publicKey, rotationHash string, existingIdentity bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, exists := s.identities[identity]
+	actuallyExists := exists && !record.deleted
+
+	if existingIdentity {
+		if !actuallyExists {
+			return fmt.Errorf("identity does not exist")
+		}
+	} else {
+		if actuallyExists {
+			return fmt.Errorf("identity already exists")
+		}
+
+		s.identities[identity] = &identityRecord{}
+		s.devices[identity] = map[string]*deviceKeyRecord{}
+	}
+
+	if _, ok := s.devices[identity][device]; ok {
+		return fmt.Errorf("device already registered")
+	}
+
+	s.devices[identity][device] = &deviceKeyRecord{
+		publicKey:    publicKey,
+		rotationHash: rotationHash,
+	}
+
+	return nil
+}
+
+func (s *AuthenticationKeyStore) Rotate(ctx context.Context, identity, device, publicKey, rotationHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, err := s.device(identity, device)
+	if err != nil {
+		return err
+	}
+
+	if record.revoked {
+		return fmt.Errorf("revoked device")
+	}
+
+	suite := s.registry.Lookup(record.rotationHash)
+	hash := suite.TagValue(suite.Hasher.Sum([]byte(publicKey)))
+
+	if !strings.EqualFold(hash, record.rotationHash) {
+		return fmt.Errorf("rotation hash does not match")
+	}
+
+	record.publicKey = publicKey
+	record.rotationHash = rotationHash
+
+	return nil
+}
+
+func (s *AuthenticationKeyStore) Public(ctx context.Context, identity, device string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, err := s.device(identity, device)
+	if err != nil {
+		return "", err
+	}
+
+	if record.revoked {
+		return "", fmt.Errorf("revoked device")
+	}
+
+	return record.publicKey, nil
+}
+
+func (s *AuthenticationKeyStore) RevokeDevice(ctx context.Context, identity, device string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, err := s.device(identity, device)
+	if err != nil {
+		return err
+	}
+
+	record.revoked = true
+
+	return nil
+}
+
+func (s *AuthenticationKeyStore) RevokeDevices(ctx context.Context, identity string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, record := range s.devices[identity] {
+		record.revoked = true
+	}
+
+	return nil
+}
+
+func (s *AuthenticationKeyStore) DeleteIdentity(ctx context.Context, identity string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, record := range s.devices[identity] {
+		record.revoked = true
+	}
+
+	record, ok := s.identities[identity]
+	if !ok {
+		return fmt.Errorf("identity not found")
+	}
+
+	record.deleted = true
+
+	return nil
+}
+
+func (s *AuthenticationKeyStore) EnsureActive(ctx context.Context, identity, device string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	identityRecord, ok := s.identities[identity]
+	if !ok {
+		return fmt.Errorf("identity not found")
+	}
+
+	if identityRecord.deleted {
+		return fmt.Errorf("identity deleted")
+	}
+
+	record, err := s.device(identity, device)
+	if err != nil {
+		return err
+	}
+
+	if record.revoked {
+		return fmt.Errorf("device revoked")
+	}
+
+	return nil
+}
+
+// device looks up a device's key record; callers must hold s.mu.
+func (s *AuthenticationKeyStore) device(identity, device string) (*deviceKeyRecord, error) {
+	devices, ok := s.devices[identity]
+	if !ok {
+		return nil, fmt.Errorf("identity not found")
+	}
+
+	record, ok := devices[device]
+	if !ok {
+		return nil, fmt.Errorf("device not found")
+	}
+
+	return record, nil
+}
+
+type nonceRecord struct {
+	identity  string
+	used      bool
+	createdAt time.Time
+}
+
+// NonceStore is an in-memory store.NonceStore.
+type NonceStore struct {
+	lifetime time.Duration
+
+	mu     sync.Mutex
+	nonces map[string]*nonceRecord
+}
+
+func NewNonceStore(lifetime time.Duration) *NonceStore {
+	return &NonceStore{
+		lifetime: lifetime,
+		nonces:   map[string]*nonceRecord{},
+	}
+}
+
+func (s *NonceStore) Generate(ctx context.Context, identity string) (string, error) {
+	buffer := make([]byte, 16)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	nonce := hex.EncodeToString(buffer)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nonces[nonce] = &nonceRecord{
+		identity:  identity,
+		createdAt: time.Now(),
+	}
+
+	return nonce, nil
+}
+
+func (s *NonceStore) Verify(ctx context.Context, nonce string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.nonces[nonce]
+	if !ok {
+		return "", fmt.Errorf("nonce not found")
+	}
+
+	if record.used {
+		return "", fmt.Errorf("challenge already used")
+	}
+
+	if record.createdAt.Add(s.lifetime).Before(time.Now()) {
+		return "", fmt.Errorf("challenge expired")
+	}
+
+	record.used = true
+
+	return record.identity, nil
+}
+
+type recoveryHashRecord struct {
+	hash         string
+	rotationHash string
+}
+
+// RecoveryHashStore is an in-memory store.RecoveryHashStore.
+type RecoveryHashStore struct {
+	hasher cryptointerfaces.Hasher
+
+	mu     sync.Mutex
+	hashes map[string]*recoveryHashRecord
+}
+
+func NewRecoveryHashStore() *RecoveryHashStore {
+	return &RecoveryHashStore{
+		hasher: crypto.NewBlake3(),
+		hashes: map[string]*recoveryHashRecord{},
+	}
+}
+
+func (s *RecoveryHashStore) Register(ctx context.Context, identity, recoveryHash, commitment string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.hashes[identity]; ok {
+		return fmt.Errorf("recovery hash already registered")
+	}
+
+	s.hashes[identity] = &recoveryHashRecord{hash: recoveryHash, rotationHash: commitment}
+
+	return nil
+}
+
+func (s *RecoveryHashStore) Rotate(ctx context.Context, identity, newHash, commitment string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.hashes[identity]
+	if !ok {
+		return fmt.Errorf("recovery hash not found")
+	}
+
+	hash := s.hasher.Sum([]byte(newHash))
+
+	if !strings.EqualFold(hash, record.rotationHash) {
+		return fmt.Errorf("rotation hash does not match")
+	}
+
+	record.hash = newHash
+	record.rotationHash = commitment
+
+	return nil
+}
+
+func (s *RecoveryHashStore) Change(ctx context.Context, identity, newHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.hashes[identity]
+	if !ok {
+		return fmt.Errorf("recovery hash not found")
+	}
+
+	record.hash = newHash
+
+	return nil
+}
+
+type recoveryAddressKey struct {
+	identity string
+	via      string
+	value    string
+}
+
+type recoveryAddressRecord struct {
+	hash     string
+	verified bool
+}
+
+// RecoveryAddressStore is an in-memory store.RecoveryAddressStore.
+type RecoveryAddressStore struct {
+	hasher cryptointerfaces.Hasher
+
+	mu        sync.Mutex
+	addresses map[recoveryAddressKey]*recoveryAddressRecord
+}
+
+func NewRecoveryAddressStore() *RecoveryAddressStore {
+	return &RecoveryAddressStore{
+		hasher:    crypto.NewBlake3(),
+		addresses: map[recoveryAddressKey]*recoveryAddressRecord{},
+	}
+}
+
+func (s *RecoveryAddressStore) Add(ctx context.Context, identity, via, value, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := recoveryAddressKey{identity: identity, via: via, value: value}
+
+	if _, ok := s.addresses[key]; ok {
+		return fmt.Errorf("recovery address already registered")
+	}
+
+	s.addresses[key] = &recoveryAddressRecord{hash: hash}
+
+	return nil
+}
+
+func (s *RecoveryAddressStore) Verify(ctx context.Context, identity, via, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash := s.hasher.Sum([]byte(token))
+
+	for key, record := range s.addresses {
+		if key.identity != identity || key.via != via {
+			continue
+		}
+
+		if !strings.EqualFold(hash, record.hash) {
+			continue
+		}
+
+		record.verified = true
+
+		return nil
+	}
+
+	return fmt.Errorf("no recovery address for %s/%s matches the supplied token", identity, via)
+}
+
+func (s *RecoveryAddressStore) List(ctx context.Context, identity string) ([]store.RecoveryAddress, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	addresses := []store.RecoveryAddress{}
+	for key, record := range s.addresses {
+		if key.identity != identity {
+			continue
+		}
+
+		addresses = append(addresses, store.RecoveryAddress{
+			Via:      key.via,
+			Value:    key.value,
+			Verified: record.verified,
+		})
+	}
+
+	return addresses, nil
+}
+
+func (s *RecoveryAddressStore) Remove(ctx context.Context, identity, via, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := recoveryAddressKey{identity: identity, via: via, value: value}
+
+	if _, ok := s.addresses[key]; !ok {
+		return fmt.Errorf("recovery address not found")
+	}
+
+	delete(s.addresses, key)
+
+	return nil
+}
+
+func (s *RecoveryAddressStore) RotateHash(ctx context.Context, identity, via, value, newHash, commitment string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := recoveryAddressKey{identity: identity, via: via, value: value}
+
+	record, ok := s.addresses[key]
+	if !ok {
+		return fmt.Errorf("recovery address not found")
+	}
+
+	hash := s.hasher.Sum([]byte(newHash))
+
+	if !strings.EqualFold(hash, record.hash) {
+		return fmt.Errorf("rotation hash does not match")
+	}
+
+	record.hash = commitment
+
+	return nil
+}
+
+// Backend opens an in-memory Stores bundle. It registers itself under the "memory" DSN
+// scheme; the DSN's path/host carry no meaning today since state isn't shared across opens.
+type Backend struct{}
+
+func NewBackend() *Backend {
+	return &Backend{}
+}
+
+func (Backend) Open(ctx context.Context, opts store.Options) (*store.Stores, error) {
+	return &store.Stores{
+		Identity:          NewIdentityStore(),
+		AuthenticationKey: NewAuthenticationKeyStore(),
+		Nonce:             NewNonceStore(opts.NonceLifetime),
+		RecoveryHash:      NewRecoveryHashStore(),
+		RecoveryAddress:   NewRecoveryAddressStore(),
+	}, nil
+}
@@ -0,0 +1,24 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/store"
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/store/conformance"
+)
+
+// TestBackendConformance runs the shared store.Backend conformance suite against the
+// in-memory backend, the only backend with no external dependency (Postgres, a Badger data
+// directory, ...) to stand up for a test run.
+func TestBackendConformance(t *testing.T) {
+	errs := conformance.Run(context.Background(), NewBackend(), store.Options{}, conformance.Config{
+		NonceLifetime: 50 * time.Millisecond,
+		Settle:        50 * time.Millisecond,
+	})
+
+	for _, err := range errs {
+		t.Error(err)
+	}
+}
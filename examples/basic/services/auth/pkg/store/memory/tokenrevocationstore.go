@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TokenRevocationStore is the in-memory store.TokenRevocationStore, for tests and
+// deployments that don't want a Redis dependency in front of it. It isn't part of Backend's
+// Stores bundle (see store.TokenRevocationStore's doc comment), so callers construct one
+// directly.
+type TokenRevocationStore struct {
+	mu      sync.Mutex
+	revoked map[string]time.Time // tokenHash -> exp
+
+	stop chan struct{}
+}
+
+// NewTokenRevocationStore starts a TokenRevocationStore whose background sweep removes
+// expired entries every sweepInterval, so a long-lived process doesn't accumulate denylist
+// entries for tokens that would be rejected as expired anyway. Callers should Close it once
+// done.
+func NewTokenRevocationStore(sweepInterval time.Duration) *TokenRevocationStore {
+	s := &TokenRevocationStore{
+		revoked: map[string]time.Time{},
+		stop:    make(chan struct{}),
+	}
+
+	go s.sweepLoop(sweepInterval)
+
+	return s
+}
+
+func (s *TokenRevocationStore) sweepLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.sweep()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *TokenRevocationStore) sweep() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for tokenHash, exp := range s.revoked {
+		if !now.Before(exp) {
+			delete(s.revoked, tokenHash)
+		}
+	}
+}
+
+// Close stops the background sweep. A stopped TokenRevocationStore still answers Revoke and
+// IsRevoked correctly; it just stops proactively evicting expired entries between calls.
+func (s *TokenRevocationStore) Close() {
+	close(s.stop)
+}
+
+func (s *TokenRevocationStore) Revoke(ctx context.Context, tokenHash string, exp time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.revoked[tokenHash] = exp
+
+	return nil
+}
+
+func (s *TokenRevocationStore) IsRevoked(ctx context.Context, tokenHash string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	exp, ok := s.revoked[tokenHash]
+	if !ok {
+		return false, nil
+	}
+
+	if !time.Now().Before(exp) {
+		delete(s.revoked, tokenHash)
+		return false, nil
+	}
+
+	return true, nil
+}
@@ -0,0 +1,88 @@
+// Package revocable wraps a store.AuthenticationKeyStore so that revoking a device or
+// deleting an identity also denylists every outstanding access token tied to it, via a
+// TokenIndex, rather than waiting for those tokens to simply expire on their own.
+package revocable
+
+import (
+	"context"
+	"time"
+)
+
+// TokenIndex is the subset of redisadapter.TokenRevocationStore this wrapper needs: enough
+// to revoke every token Track has recorded for a device or an identity. It's expressed as an
+// interface here, rather than importing redisadapter directly, so tests can supply an
+// in-memory stand-in.
+type TokenIndex interface {
+	RevokeAllForDevice(ctx context.Context, identity, device string, exp time.Time) error
+	RevokeAllForIdentity(ctx context.Context, identity string, exp time.Time) error
+}
+
+// AuthenticationKeyStore matches store.AuthenticationKeyStore's method set (it isn't declared
+// against that interface directly to avoid an import cycle with pkg/store, which doesn't
+// need to know this wrapper exists).
+type AuthenticationKeyStore interface {
+	Register(ctx context.Context, identity, device, publicKey, rotationHash string, existingIdentity bool) error
+	Rotate(ctx context.Context, identity, device, publicKey, rotationHash string) error
+	Public(ctx context.Context, identity, device string) (string, error)
+	RevokeDevice(ctx context.Context, identity, device string) error
+	RevokeDevices(ctx context.Context, identity string) error
+	DeleteIdentity(ctx context.Context, identity string) error
+	EnsureActive(ctx context.Context, identity, device string) error
+}
+
+// Wrapper wraps an AuthenticationKeyStore with a TokenIndex. denylistWindow bounds how long a
+// newly-revoked device or identity's tokens stay denylisted, since the wrapper only learns
+// that a revocation happened, not each token's own remaining lifetime.
+type Wrapper struct {
+	backend        AuthenticationKeyStore
+	index          TokenIndex
+	denylistWindow time.Duration
+}
+
+func NewWrapper(backend AuthenticationKeyStore, index TokenIndex, denylistWindow time.Duration) *Wrapper {
+	return &Wrapper{
+		backend:        backend,
+		index:          index,
+		denylistWindow: denylistWindow,
+	}
+}
+
+func (s *Wrapper) Register(ctx context.Context, identity, device, publicKey, rotationHash string, existingIdentity bool) error {
+	return s.backend.Register(ctx, identity, device, publicKey, rotationHash, existingIdentity)
+}
+
+func (s *Wrapper) Rotate(ctx context.Context, identity, device, publicKey, rotationHash string) error {
+	return s.backend.Rotate(ctx, identity, device, publicKey, rotationHash)
+}
+
+func (s *Wrapper) Public(ctx context.Context, identity, device string) (string, error) {
+	return s.backend.Public(ctx, identity, device)
+}
+
+func (s *Wrapper) RevokeDevice(ctx context.Context, identity, device string) error {
+	if err := s.backend.RevokeDevice(ctx, identity, device); err != nil {
+		return err
+	}
+
+	return s.index.RevokeAllForDevice(ctx, identity, device, time.Now().Add(s.denylistWindow))
+}
+
+func (s *Wrapper) RevokeDevices(ctx context.Context, identity string) error {
+	if err := s.backend.RevokeDevices(ctx, identity); err != nil {
+		return err
+	}
+
+	return s.index.RevokeAllForIdentity(ctx, identity, time.Now().Add(s.denylistWindow))
+}
+
+func (s *Wrapper) DeleteIdentity(ctx context.Context, identity string) error {
+	if err := s.backend.DeleteIdentity(ctx, identity); err != nil {
+		return err
+	}
+
+	return s.index.RevokeAllForIdentity(ctx, identity, time.Now().Add(s.denylistWindow))
+}
+
+func (s *Wrapper) EnsureActive(ctx context.Context, identity, device string) error {
+	return s.backend.EnsureActive(ctx, identity, device)
+}
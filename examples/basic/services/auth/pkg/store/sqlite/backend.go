@@ -0,0 +1,71 @@
+// Package sqlite provides a store.Backend backed by verifiable-storage-go over SQLite,
+// registered under the "sqlite" DSN scheme (e.g. "sqlite:///var/lib/auth.db"), for
+// deployments and local development that want the same verifiable chain semantics as the
+// Postgres backend without a running database server.
+package sqlite
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/db"
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/models"
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/store"
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/store/verifiablesql"
+)
+
+// Backend opens a Stores bundle backed by verifiable-storage-go over SQLite. It reuses the
+// same verifiablesql store implementations the Postgres backend uses, since neither depends
+// on anything Postgres-specific beyond the data.Store it's handed.
+type Backend struct{}
+
+func NewBackend() *Backend {
+	return &Backend{}
+}
+
+func (Backend) Open(ctx context.Context, opts store.Options) (*store.Stores, error) {
+	parsed, err := url.Parse(opts.DSN)
+	if err != nil {
+		return nil, err
+	}
+
+	// "sqlite:///path/to/auth.db" -> "path/to/auth.db"; "sqlite://:memory:" -> ":memory:",
+	// matching modernc.org/sqlite's own DSN conventions once the "sqlite://" framing is gone.
+	path := parsed.Path
+	if path == "" {
+		path = parsed.Host
+	}
+
+	migrations := []string{
+		models.IDENTITY_TABLE_SQL,
+		models.AUTHENTICATION_KEYS_TABLE_SQL,
+		models.AUTHENTICATION_NONCE_TABLE_SQL,
+		models.RECOVERY_HASH_TABLE_SQL,
+		models.RECOVERY_ADDRESS_TABLE_SQL,
+	}
+	migrations = append(migrations, opts.Migrations...)
+
+	sqlStore, err := db.NewSQLiteStore(ctx, path, migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	authenticationKeyStore, err := verifiablesql.NewAuthenticationKeyStore(sqlStore)
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimit := verifiablesql.RateLimitConfig{
+		Window:           opts.NonceRateLimit.Window,
+		BurstPerIdentity: opts.NonceRateLimit.BurstPerIdentity,
+		BurstPerIP:       opts.NonceRateLimit.BurstPerIP,
+	}
+
+	return &store.Stores{
+		Identity:          verifiablesql.NewIdentityStore(sqlStore),
+		AuthenticationKey: authenticationKeyStore,
+		Nonce:             verifiablesql.NewNonceStore(sqlStore, opts.NonceLifetime, rateLimit),
+		RecoveryHash:      verifiablesql.NewRecoveryHashStore(sqlStore, opts.RecoverySigner),
+		RecoveryAddress:   verifiablesql.NewRecoveryAddressStore(sqlStore),
+	}, nil
+}
@@ -0,0 +1,24 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/store"
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/store/conformance"
+)
+
+// TestBackendConformance runs the shared store.Backend conformance suite against the SQLite
+// backend, opened in-memory. SQLite needs no external service to stand up, so unlike Postgres
+// there's no excuse for this backend to skip the suite.
+func TestBackendConformance(t *testing.T) {
+	errs := conformance.Run(context.Background(), NewBackend(), store.Options{DSN: "sqlite://:memory:"}, conformance.Config{
+		NonceLifetime: 50 * time.Millisecond,
+		Settle:        50 * time.Millisecond,
+	})
+
+	for _, err := range errs {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,172 @@
+// Package store declares the narrow interfaces this service needs from its persistence
+// layer, independent of any particular backend. pkg/implementation used to wire directly
+// to verifiable-storage-go's SQL-backed repositories; that coupling now lives behind these
+// interfaces in pkg/store/verifiablesql, alongside pkg/store/memory (for tests) and
+// pkg/store/badger (for embedded deployments without a Postgres instance).
+package store
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/jasoncolburne/better-auth-go/pkg/cryptointerfaces"
+)
+
+// IdentityStore tracks whether an identity prefix has been registered or deleted,
+// independent of any particular device's authentication keys.
+type IdentityStore interface {
+	Exists(ctx context.Context, identity string) (bool, error)
+	Delete(ctx context.Context, identity string) error
+}
+
+// AuthenticationKeyStore matches the method set pkg/implementation.AuthenticationKeyStore
+// already exposed when it was hard-wired to verifiable-storage-go.
+type AuthenticationKeyStore interface {
+	Register(ctx context.Context, identity, device, publicKey, rotationHash string, existingIdentity bool) error
+	Rotate(ctx context.Context, identity, device, publicKey, rotationHash string) error
+	Public(ctx context.Context, identity, device string) (string, error)
+	RevokeDevice(ctx context.Context, identity, device string) error
+	RevokeDevices(ctx context.Context, identity string) error
+	DeleteIdentity(ctx context.Context, identity string) error
+	EnsureActive(ctx context.Context, identity, device string) error
+}
+
+// NonceStore matches the method set of the garden-k8s AuthenticationNonceStore.
+type NonceStore interface {
+	Generate(ctx context.Context, identity string) (string, error)
+	Verify(ctx context.Context, nonce string) (string, error)
+}
+
+// RecoveryHashStore matches the method set of the garden-k8s RecoveryHashStore, plus the
+// commitment-based Rotate discipline AuthenticationKeyStore already enforces for device
+// keys: Register and Rotate each take the commitment for the value that will replace the
+// one being set, and Rotate checks the incoming hash against the commitment left by the
+// previous call before accepting it.
+type RecoveryHashStore interface {
+	Register(ctx context.Context, identity, recoveryHash, commitment string) error
+	Rotate(ctx context.Context, identity, newHash, commitment string) error
+	Change(ctx context.Context, identity, newHash string) error
+}
+
+// RecoveryAddressStore tracks per-identity recovery channels (email, SMS, backup-code set,
+// hardware token attestation, ...) keyed by (identity, via, value), alongside the single
+// account-wide secret RecoveryHashStore models. RecoveryHashStore's existing behavior is
+// equivalent to one such address with via == "hash"; this interface doesn't replace it, it
+// gives deployments room for more than one recovery channel per identity.
+type RecoveryAddressStore interface {
+	// Add registers a new (via, value) channel for identity with hash as the pre-committed
+	// hash a caller must later present a matching token for via Verify.
+	Add(ctx context.Context, identity, via, value, hash string) error
+
+	// Verify checks token against every unremoved (identity, via) address's committed hash
+	// and marks the first match verified. Verifying one address never touches another's
+	// state, so previously-verified channels survive unrelated identity changes untouched.
+	Verify(ctx context.Context, identity, via, token string) error
+
+	// List returns every unremoved recovery address registered for identity.
+	List(ctx context.Context, identity string) ([]RecoveryAddress, error)
+
+	// Remove soft-deletes the (identity, via, value) address; it no longer participates in
+	// Verify or List.
+	Remove(ctx context.Context, identity, via, value string) error
+
+	// RotateHash replaces (identity, via, value)'s committed hash, the same pre-committed
+	// discipline RecoveryHashStore.Rotate enforces: newHash must hash to the value's current
+	// commitment before commitment becomes the new one.
+	RotateHash(ctx context.Context, identity, via, value, newHash, commitment string) error
+}
+
+// RecoveryAddress is the read shape RecoveryAddressStore.List hands back.
+type RecoveryAddress struct {
+	Via      string
+	Value    string
+	Verified bool
+}
+
+// TokenRevocationStore lets an operator or user actively revoke an already-issued access
+// token before it would otherwise expire, the way AuthenticationKeyStore.RevokeDevice revokes
+// a device's authentication key rather than one outstanding token. It's deliberately not a
+// field on Stores: it's keyed by token hash and bounded by the token's own remaining
+// lifetime rather than the verifiable identity chain the rest of Stores models, so a
+// deployment wires it up alongside a Backend rather than through one.
+type TokenRevocationStore interface {
+	// Revoke denylists tokenHash until exp; implementations may discard the entry once exp
+	// has passed, since a token that has simply expired needs no denylist entry to be
+	// rejected.
+	Revoke(ctx context.Context, tokenHash string, exp time.Time) error
+
+	// IsRevoked reports whether tokenHash is currently denylisted.
+	IsRevoked(ctx context.Context, tokenHash string) (bool, error)
+}
+
+// Options bundles everything a backend's Open might need; a given backend only reads the
+// fields relevant to it. DSN is always set by Registry.Open from the dial string itself.
+type Options struct {
+	DSN           string
+	NonceLifetime time.Duration
+	Migrations    []string
+
+	// NonceRateLimit bounds a NonceStore's Generate calls; a zero value disables the
+	// per-identity/per-IP quota entirely, which backends should treat as "unbounded".
+	NonceRateLimit NonceRateLimit
+
+	// RecoverySigner signs the chain receipts RecoveryHashStore's read API hands back, so a
+	// client can verify the current recovery hash offline against this server's known
+	// public key. Backends without a verifiable chain to attest to may ignore this field.
+	RecoverySigner cryptointerfaces.SigningKey
+}
+
+// NonceRateLimit configures a NonceStore's sliding-window Generate quota.
+type NonceRateLimit struct {
+	Window           time.Duration
+	BurstPerIdentity int
+	BurstPerIP       int
+}
+
+// Stores is the full set a Backend hands back from Open.
+type Stores struct {
+	Identity          IdentityStore
+	AuthenticationKey AuthenticationKeyStore
+	Nonce             NonceStore
+	RecoveryHash      RecoveryHashStore
+	RecoveryAddress   RecoveryAddressStore
+}
+
+// Backend opens a Stores bundle for one DSN scheme.
+type Backend interface {
+	Open(ctx context.Context, opts Options) (*Stores, error)
+}
+
+// Registry dispatches Open calls to the Backend registered for a DSN's scheme, the way
+// gobuffalo/pop dispatches a connection URL to a dialect.
+type Registry struct {
+	backends map[string]Backend
+}
+
+func NewRegistry() *Registry {
+	return &Registry{backends: map[string]Backend{}}
+}
+
+func (r *Registry) Register(scheme string, backend Backend) {
+	r.backends[scheme] = backend
+}
+
+// Open parses dsn's scheme (e.g. "memory://", "postgres://", "badger:///path") and opens a
+// Stores bundle from the backend registered for it.
+func (r *Registry) Open(ctx context.Context, dsn string, opts Options) (*Stores, error) {
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid store dsn %q: %w", dsn, err)
+	}
+
+	backend, ok := r.backends[parsed.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("no store backend registered for scheme %q", parsed.Scheme)
+	}
+
+	opts.DSN = dsn
+
+	return backend.Open(ctx, opts)
+}
@@ -1,12 +1,13 @@
-package implementation
+package verifiablesql
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 
-	"github.com/jasoncolburne/better-auth-go/examples/crypto"
-	"github.com/jasoncolburne/better-auth-go/pkg/cryptointerfaces"
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/cryptosuite"
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/did"
 	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/models"
 	"github.com/jasoncolburne/verifiable-storage-go/pkg/data"
 	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/clauses"
@@ -15,17 +16,20 @@ import (
 	"github.com/jasoncolburne/verifiable-storage-go/pkg/repository"
 )
 
+// AuthenticationKeyStore is the verifiable-storage-go-backed store.AuthenticationKeyStore
+// implementation. It also satisfies did.AuthenticationKeySource so the did:keri and
+// did:webs resolvers can assemble a DID document straight from this store's data.
 type AuthenticationKeyStore struct {
-	store  data.Store
-	hasher cryptointerfaces.Hasher
+	store    data.Store
+	registry *cryptosuite.Registry
 
 	identityRepository           repository.Repository[*models.Identity]
 	authenticationKeysRepository repository.Repository[*models.AuthenticationKeys]
+
+	resolver *did.Registry
 }
 
 func NewAuthenticationKeyStore(store data.Store) (*AuthenticationKeyStore, error) {
-	hasher := crypto.NewBlake3()
-
 	identityRepository := repository.NewVerifiableRepository[*models.Identity](
 		store,
 		true,
@@ -40,12 +44,79 @@ func NewAuthenticationKeyStore(store data.Store) (*AuthenticationKeyStore, error
 		nil, // nil for determinism
 	)
 
-	return &AuthenticationKeyStore{
+	s := &AuthenticationKeyStore{
 		store:                        store,
-		hasher:                       hasher,
+		registry:                     cryptosuite.Default,
 		identityRepository:           identityRepository,
 		authenticationKeysRepository: authenticationKeysRepository,
-	}, nil
+	}
+
+	resolver := did.NewRegistry()
+	resolver.Register("keri", did.NewKeriResolver(s))
+	resolver.Register("webs", did.NewWebsResolver(s, ""))
+	s.resolver = resolver
+
+	return s, nil
+}
+
+// ActiveDeviceKeys implements did.AuthenticationKeySource so the did:keri and did:webs
+// resolvers can assemble a DID document straight from this store's data.
+func (s AuthenticationKeyStore) ActiveDeviceKeys(ctx context.Context, identity string) ([]did.DeviceKey, error) {
+	records := []*models.AuthenticationKeys{}
+
+	if err := s.authenticationKeysRepository.ListLatestByPrefix(
+		ctx,
+		&records,
+		expressions.Equal("identity", identity),
+		expressions.NotEqual("revoked", true),
+		nil,
+		nil,
+	); err != nil {
+		return nil, err
+	}
+
+	deviceKeys := make([]did.DeviceKey, 0, len(records))
+	for _, record := range records {
+		deviceKeys = append(deviceKeys, did.DeviceKey{
+			Device:       record.Device,
+			PublicKey:    record.PublicKey,
+			RotationHash: record.RotationHash,
+		})
+	}
+
+	return deviceKeys, nil
+}
+
+// Publish produces the DID document for identity (which may be a raw prefix or a did:keri /
+// did:webs DID) as JSON, suitable for handing to a relying party or, for did:webs, hosting at
+// the resolver's well-known path.
+func (s AuthenticationKeyStore) Publish(ctx context.Context, identity string) ([]byte, error) {
+	didStr := identity
+	if !did.IsDID(didStr) {
+		didStr = fmt.Sprintf("did:keri:%s", identity)
+	}
+
+	document, err := s.resolver.Resolve(ctx, didStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(document)
+}
+
+// prefixFor resolves identity to a raw identity prefix, dispatching through the DID
+// registry when identity is itself a DID rather than a bare prefix.
+func (s AuthenticationKeyStore) prefixFor(identity string) (string, error) {
+	if !did.IsDID(identity) {
+		return identity, nil
+	}
+
+	_, prefix, err := did.Parse(identity)
+	if err != nil {
+		return "", err
+	}
+
+	return prefix, nil
 }
 
 func (s AuthenticationKeyStore) identityExists(ctx context.Context, identity string) (bool, error) {
@@ -148,7 +219,8 @@ func (s AuthenticationKeyStore) Rotate(ctx context.Context, identity, device, pu
 		return fmt.Errorf("revoked device")
 	}
 
-	hash := s.hasher.Sum([]byte(publicKey))
+	suite := s.registry.Lookup(record.RotationHash)
+	hash := suite.TagValue(suite.Hasher.Sum([]byte(publicKey)))
 
 	if !strings.EqualFold(hash, record.RotationHash) {
 		return fmt.Errorf("rotation hash does not match")
@@ -260,12 +332,19 @@ func (s AuthenticationKeyStore) DeleteIdentity(ctx context.Context, identity str
 	return nil
 }
 
+// EnsureActive accepts either a raw identity prefix or a did:keri/did:webs DID and checks
+// that the identity is not deleted and the device's authentication key is not revoked.
 func (s AuthenticationKeyStore) EnsureActive(ctx context.Context, identity, device string) error {
+	prefix, err := s.prefixFor(identity)
+	if err != nil {
+		return err
+	}
+
 	identityRecord := &models.Identity{}
 	if err := s.identityRepository.Get(
 		ctx,
 		identityRecord,
-		expressions.Equal("identity", identity),
+		expressions.Equal("identity", prefix),
 		orderings.Descending("sequence_number"),
 	); err != nil {
 		return err
@@ -281,7 +360,7 @@ func (s AuthenticationKeyStore) EnsureActive(ctx context.Context, identity, devi
 		ctx,
 		keysRecord,
 		clauses.And([]data.ClauseOrExpression{
-			expressions.Equal("identity", identity),
+			expressions.Equal("identity", prefix),
 			expressions.Equal("device", device),
 		}),
 		orderings.Descending("sequence_number"),
@@ -0,0 +1,53 @@
+package verifiablesql
+
+import (
+	"context"
+
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/db"
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/models"
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/store"
+)
+
+// Backend opens a Stores bundle backed by verifiable-storage-go over a SQL database. It
+// registers itself under the "postgres" DSN scheme; only Postgres is wired up today, but
+// nothing here is Postgres-specific beyond db.NewPostgreSQLStore.
+type Backend struct{}
+
+func NewBackend() *Backend {
+	return &Backend{}
+}
+
+func (Backend) Open(ctx context.Context, opts store.Options) (*store.Stores, error) {
+	migrations := []string{
+		models.IDENTITY_TABLE_SQL,
+		models.AUTHENTICATION_KEYS_TABLE_SQL,
+		models.AUTHENTICATION_NONCE_TABLE_SQL,
+		models.RECOVERY_HASH_TABLE_SQL,
+		models.RECOVERY_ADDRESS_TABLE_SQL,
+	}
+	migrations = append(migrations, opts.Migrations...)
+
+	sqlStore, err := db.NewPostgreSQLStore(ctx, opts.DSN, migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	authenticationKeyStore, err := NewAuthenticationKeyStore(sqlStore)
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimit := RateLimitConfig{
+		Window:           opts.NonceRateLimit.Window,
+		BurstPerIdentity: opts.NonceRateLimit.BurstPerIdentity,
+		BurstPerIP:       opts.NonceRateLimit.BurstPerIP,
+	}
+
+	return &store.Stores{
+		Identity:          NewIdentityStore(sqlStore),
+		AuthenticationKey: authenticationKeyStore,
+		Nonce:             NewNonceStore(sqlStore, opts.NonceLifetime, rateLimit),
+		RecoveryHash:      NewRecoveryHashStore(sqlStore, opts.RecoverySigner),
+		RecoveryAddress:   NewRecoveryAddressStore(sqlStore),
+	}, nil
+}
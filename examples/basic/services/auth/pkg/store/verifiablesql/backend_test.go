@@ -0,0 +1,30 @@
+package verifiablesql
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/store"
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/store/conformance"
+)
+
+// TestBackendConformance runs the shared store.Backend conformance suite against the
+// Postgres-backed verifiablesql backend. Unlike SQLite, this needs a running Postgres
+// instance, so it skips — rather than fails — when TEST_POSTGRES_DSN isn't set.
+func TestBackendConformance(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN not set; skipping Postgres-backed conformance test")
+	}
+
+	errs := conformance.Run(context.Background(), NewBackend(), store.Options{DSN: dsn}, conformance.Config{
+		NonceLifetime: 50 * time.Millisecond,
+		Settle:        50 * time.Millisecond,
+	})
+
+	for _, err := range errs {
+		t.Error(err)
+	}
+}
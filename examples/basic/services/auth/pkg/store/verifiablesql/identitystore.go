@@ -0,0 +1,76 @@
+package verifiablesql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/models"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/expressions"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/orderings"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/repository"
+)
+
+// IdentityStore is the verifiable-storage-go-backed store.IdentityStore implementation.
+// AuthenticationKeyStore keeps its own identityRepository rather than depending on this
+// type directly, since it needs identity existence checks inside the same transaction it
+// uses to create authentication key records.
+type IdentityStore struct {
+	repository repository.Repository[*models.Identity]
+}
+
+func NewIdentityStore(store data.Store) *IdentityStore {
+	return &IdentityStore{
+		repository: repository.NewVerifiableRepository[*models.Identity](
+			store,
+			true,
+			true,
+			nil, // nil for determinism
+		),
+	}
+}
+
+func (s IdentityStore) Exists(ctx context.Context, identity string) (bool, error) {
+	buffer := []*models.Identity{}
+
+	if err := s.repository.ListLatestByPrefix(
+		ctx,
+		&buffer,
+		expressions.Equal("identity", identity),
+		expressions.Equal("deleted", false),
+		nil,
+		nil,
+	); err != nil {
+		return false, err
+	}
+
+	if len(buffer) == 0 {
+		return false, nil
+	}
+
+	if buffer[0].Deleted {
+		return false, fmt.Errorf("account deleted")
+	}
+
+	return true, nil
+}
+
+func (s IdentityStore) Delete(ctx context.Context, identity string) error {
+	record := &models.Identity{}
+	if err := s.repository.Get(
+		ctx,
+		record,
+		expressions.Equal("identity", identity),
+		orderings.Descending("sequence_number"),
+	); err != nil {
+		return err
+	}
+
+	if record.Deleted {
+		return nil
+	}
+
+	record.Deleted = true
+
+	return s.repository.CreateVersion(ctx, record)
+}
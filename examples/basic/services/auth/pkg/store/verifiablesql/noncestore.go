@@ -0,0 +1,301 @@
+package verifiablesql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jasoncolburne/better-auth-go/examples/crypto"
+	"github.com/jasoncolburne/better-auth-go/pkg/cryptointerfaces"
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/models"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/expressions"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/orderings"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/repository"
+)
+
+// clientIPContextKey is unexported so only ContextWithClientIP can populate it.
+type clientIPContextKey struct{}
+
+// ContextWithClientIP threads a caller's IP through ctx so Generate can apply its optional
+// per-IP quota alongside the per-identity one.
+func ContextWithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPContextKey{}, ip)
+}
+
+func clientIPFromContext(ctx context.Context) (string, bool) {
+	ip, ok := ctx.Value(clientIPContextKey{}).(string)
+	return ip, ok && ip != ""
+}
+
+// ErrRateLimited is returned by Generate when an identity (or client IP) has exceeded its
+// nonce issuance quota for the current window, so HTTP handlers can surface a 429 with a
+// Retry-After header from RetryAfter.
+type ErrRateLimited struct {
+	Identity   string
+	RetryAfter time.Duration
+}
+
+func (e *ErrRateLimited) Error() string {
+	return fmt.Sprintf("rate limited: identity %q may retry in %s", e.Identity, e.RetryAfter)
+}
+
+// RateLimitConfig bounds how many unused, unexpired nonces Generate will allow to exist for
+// an identity (and, optionally, a client IP) within a sliding Window.
+type RateLimitConfig struct {
+	Window           time.Duration
+	BurstPerIdentity int
+	BurstPerIP       int // 0 disables the per-IP check
+}
+
+// NonceStore is the verifiable-storage-go-backed store.NonceStore implementation. Alongside
+// Generate/Verify it runs a background sweeper that marks nonces past their lifetime as
+// expired, so a client that never completes the challenge doesn't count against its quota
+// forever and the table's unused-nonce backlog doesn't grow without bound.
+type NonceStore struct {
+	lifetime  time.Duration
+	rateLimit RateLimitConfig
+
+	noncer                        cryptointerfaces.Noncer
+	identityRepository            repository.Repository[*models.Identity]
+	authenticationNonceRepository repository.Repository[*models.AuthenticationNonce]
+
+	stopSweep chan struct{}
+}
+
+func NewNonceStore(store data.Store, lifetime time.Duration, rateLimit RateLimitConfig) *NonceStore {
+	noncer := crypto.NewNoncer()
+
+	identityRepository := repository.NewVerifiableRepository[*models.Identity](
+		store,
+		true,
+		true,
+		nil, // nil for determinism
+	)
+
+	authenticationNonceRepository := repository.NewVerifiableRepository[*models.AuthenticationNonce](
+		store,
+		true,
+		true,
+		nil,
+	)
+
+	s := &NonceStore{
+		lifetime:                      lifetime,
+		rateLimit:                     rateLimit,
+		noncer:                        noncer,
+		identityRepository:            identityRepository,
+		authenticationNonceRepository: authenticationNonceRepository,
+		stopSweep:                     make(chan struct{}),
+	}
+
+	go s.sweep()
+
+	return s
+}
+
+// Close stops the background sweeper. It does not close the underlying data.Store.
+func (s *NonceStore) Close() {
+	close(s.stopSweep)
+}
+
+func (s *NonceStore) Generate(ctx context.Context, identity string) (string, error) {
+	identityRecord := &models.Identity{}
+	if err := s.identityRepository.Get(
+		ctx,
+		identityRecord,
+		expressions.Equal("identity", identity),
+		orderings.Descending("sequence_number"),
+	); err != nil {
+		return "", err
+	}
+
+	if !strings.EqualFold(identityRecord.Identity, identity) {
+		return "", fmt.Errorf("mismatched identity")
+	}
+
+	if identityRecord.Deleted {
+		return "", fmt.Errorf("deleted identity")
+	}
+
+	if err := s.checkRateLimit(ctx, identity); err != nil {
+		return "", err
+	}
+
+	nonce, err := s.noncer.Generate128()
+	if err != nil {
+		return "", err
+	}
+
+	record := &models.AuthenticationNonce{
+		Identity:       identity,
+		ChallengeNonce: nonce,
+	}
+
+	if ip, ok := clientIPFromContext(ctx); ok {
+		record.ClientIP = ip
+	}
+
+	if err := s.authenticationNonceRepository.CreateVersion(ctx, record); err != nil {
+		return "", err
+	}
+
+	return nonce, nil
+}
+
+// checkRateLimit enforces the sliding-window quota: Generate is rejected once more than
+// BurstPerIdentity unused, unexpired nonces were issued for identity within the last Window,
+// and likewise per client IP when BurstPerIP is configured and the caller threaded one through
+// ctx via ContextWithClientIP.
+func (s *NonceStore) checkRateLimit(ctx context.Context, identity string) error {
+	if s.rateLimit.BurstPerIdentity == 0 && s.rateLimit.BurstPerIP == 0 {
+		return nil
+	}
+
+	if s.rateLimit.BurstPerIdentity > 0 {
+		count, oldest, err := s.activeNoncesSince(ctx, expressions.Equal("identity", identity))
+		if err != nil {
+			return err
+		}
+
+		if count >= s.rateLimit.BurstPerIdentity {
+			return &ErrRateLimited{Identity: identity, RetryAfter: time.Until(oldest.Add(s.rateLimit.Window))}
+		}
+	}
+
+	if s.rateLimit.BurstPerIP == 0 {
+		return nil
+	}
+
+	ip, ok := clientIPFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	count, oldest, err := s.activeNoncesSince(ctx, expressions.Equal("client_ip", ip))
+	if err != nil {
+		return err
+	}
+
+	if count >= s.rateLimit.BurstPerIP {
+		return &ErrRateLimited{Identity: identity, RetryAfter: time.Until(oldest.Add(s.rateLimit.Window))}
+	}
+
+	return nil
+}
+
+// activeNoncesSince counts unused, unexpired nonces matching filter that were created within
+// the current Window, returning the oldest matching created_at so callers can compute
+// RetryAfter.
+func (s *NonceStore) activeNoncesSince(ctx context.Context, filter data.ClauseOrExpression) (int, time.Time, error) {
+	records := []*models.AuthenticationNonce{}
+
+	if err := s.authenticationNonceRepository.ListLatestByPrefix(
+		ctx,
+		&records,
+		filter,
+		expressions.Equal("used", false),
+		nil,
+		nil,
+	); err != nil {
+		return 0, time.Time{}, err
+	}
+
+	cutoff := time.Now().Add(-s.rateLimit.Window)
+
+	count := 0
+	var oldest time.Time
+
+	for _, record := range records {
+		if record.Expired {
+			continue
+		}
+
+		createdAt := (*time.Time)(record.CreatedAt)
+		if createdAt.Before(cutoff) {
+			continue
+		}
+
+		count++
+		if oldest.IsZero() || createdAt.Before(oldest) {
+			oldest = *createdAt
+		}
+	}
+
+	return count, oldest, nil
+}
+
+func (s *NonceStore) Verify(ctx context.Context, nonce string) (string, error) {
+	record := &models.AuthenticationNonce{}
+	if err := s.authenticationNonceRepository.Get(ctx, record, expressions.Equal("challenge_nonce", nonce), orderings.Descending("sequence_number")); err != nil {
+		return "", err
+	}
+
+	if record.Used {
+		return "", fmt.Errorf("challenge already used")
+	}
+
+	timestamp := (*time.Time)(record.CreatedAt)
+
+	if timestamp.Add(s.lifetime).Before(time.Now()) {
+		return "", fmt.Errorf("challenge expired")
+	}
+
+	record.Used = true
+
+	if err := s.authenticationNonceRepository.CreateVersion(ctx, record); err != nil {
+		return "", err
+	}
+
+	return record.Identity, nil
+}
+
+// sweep periodically marks nonces past their lifetime as expired so they stop counting
+// against Generate's rate limit and the table's live unused-nonce set stays bounded.
+func (s *NonceStore) sweep() {
+	ticker := time.NewTicker(s.lifetime)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.sweepOnce(context.Background()); err != nil {
+				fmt.Printf("nonce sweep failed: %v\n", err)
+			}
+		case <-s.stopSweep:
+			return
+		}
+	}
+}
+
+func (s *NonceStore) sweepOnce(ctx context.Context) error {
+	records := []*models.AuthenticationNonce{}
+
+	if err := s.authenticationNonceRepository.ListLatestByPrefix(
+		ctx,
+		&records,
+		expressions.Equal("used", false),
+		expressions.Equal("expired", false),
+		nil,
+		nil,
+	); err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		createdAt := (*time.Time)(record.CreatedAt)
+		if !createdAt.Add(s.lifetime).Before(time.Now()) {
+			continue
+		}
+
+		record.Used = true
+		record.Expired = true
+
+		if err := s.authenticationNonceRepository.CreateVersion(ctx, record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
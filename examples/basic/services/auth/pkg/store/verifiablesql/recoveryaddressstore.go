@@ -0,0 +1,157 @@
+package verifiablesql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jasoncolburne/better-auth-go/examples/crypto"
+	"github.com/jasoncolburne/better-auth-go/pkg/cryptointerfaces"
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/models"
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/store"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/clauses"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/expressions"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/orderings"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/repository"
+)
+
+// RecoveryAddressStore is the verifiable-storage-go-backed store.RecoveryAddressStore
+// implementation.
+type RecoveryAddressStore struct {
+	recoveryAddressRepository repository.Repository[*models.RecoveryAddress]
+	hasher                    cryptointerfaces.Hasher
+}
+
+func NewRecoveryAddressStore(store data.Store) *RecoveryAddressStore {
+	return &RecoveryAddressStore{
+		recoveryAddressRepository: repository.NewVerifiableRepository[*models.RecoveryAddress](store, true, true, nil),
+		hasher:                    crypto.NewBlake3(),
+	}
+}
+
+func (s RecoveryAddressStore) Add(ctx context.Context, identity, via, value, hash string) error {
+	record := &models.RecoveryAddress{
+		Identity: identity,
+		Via:      via,
+		Value:    value,
+		Hash:     hash,
+	}
+
+	// uniqueness constraint protects us from registering the same (identity, via, value) twice
+	return s.recoveryAddressRepository.CreateVersion(ctx, record)
+}
+
+// addresses returns the latest version of every unremoved recovery address registered for
+// identity under via, or every via if via is "".
+func (s RecoveryAddressStore) addresses(ctx context.Context, identity, via string) ([]*models.RecoveryAddress, error) {
+	records := []*models.RecoveryAddress{}
+
+	clause := expressions.Equal("identity", identity)
+	if via != "" {
+		clause = clauses.And([]data.ClauseOrExpression{
+			expressions.Equal("identity", identity),
+			expressions.Equal("via", via),
+		})
+	}
+
+	if err := s.recoveryAddressRepository.ListLatestByPrefix(
+		ctx,
+		&records,
+		clause,
+		expressions.NotEqual("removed", true),
+		nil,
+		nil,
+	); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func (s RecoveryAddressStore) Verify(ctx context.Context, identity, via, token string) error {
+	records, err := s.addresses(ctx, identity, via)
+	if err != nil {
+		return err
+	}
+
+	hash := s.hasher.Sum([]byte(token))
+
+	for _, record := range records {
+		if !strings.EqualFold(hash, record.Hash) {
+			continue
+		}
+
+		record.Verified = true
+
+		return s.recoveryAddressRepository.CreateVersion(ctx, record)
+	}
+
+	return fmt.Errorf("no recovery address for %s/%s matches the supplied token", identity, via)
+}
+
+func (s RecoveryAddressStore) List(ctx context.Context, identity string) ([]store.RecoveryAddress, error) {
+	records, err := s.addresses(ctx, identity, "")
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]store.RecoveryAddress, 0, len(records))
+	for _, record := range records {
+		addresses = append(addresses, store.RecoveryAddress{
+			Via:      record.Via,
+			Value:    record.Value,
+			Verified: record.Verified,
+		})
+	}
+
+	return addresses, nil
+}
+
+func (s RecoveryAddressStore) Remove(ctx context.Context, identity, via, value string) error {
+	record := &models.RecoveryAddress{}
+
+	if err := s.recoveryAddressRepository.Get(
+		ctx,
+		record,
+		clauses.And([]data.ClauseOrExpression{
+			expressions.Equal("identity", identity),
+			expressions.Equal("via", via),
+			expressions.Equal("value", value),
+		}),
+		orderings.Descending("sequence_number"),
+	); err != nil {
+		return err
+	}
+
+	record.Removed = true
+
+	return s.recoveryAddressRepository.CreateVersion(ctx, record)
+}
+
+func (s RecoveryAddressStore) RotateHash(ctx context.Context, identity, via, value, newHash, commitment string) error {
+	record := &models.RecoveryAddress{}
+
+	if err := s.recoveryAddressRepository.Get(
+		ctx,
+		record,
+		clauses.And([]data.ClauseOrExpression{
+			expressions.Equal("identity", identity),
+			expressions.Equal("via", via),
+			expressions.Equal("value", value),
+		}),
+		orderings.Descending("sequence_number"),
+	); err != nil {
+		return err
+	}
+
+	hash := s.hasher.Sum([]byte(newHash))
+
+	if !strings.EqualFold(hash, record.Hash) {
+		return fmt.Errorf("rotation hash does not match")
+	}
+
+	record.Hash = commitment
+
+	return s.recoveryAddressRepository.CreateVersion(ctx, record)
+}
@@ -0,0 +1,217 @@
+package verifiablesql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jasoncolburne/better-auth-go/examples/crypto"
+	"github.com/jasoncolburne/better-auth-go/pkg/cryptointerfaces"
+	"github.com/jasoncolburne/better-auth/examples/basic/auth/pkg/models"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/algorithms"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/expressions"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/orderings"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/repository"
+)
+
+// RecoveryHashReceiptBody is the payload RecoveryHashStore.Current signs: the current
+// recovery hash plus enough of its chain position (id, sequence number) for a client to
+// verify the signature offline against this server's known recovery public key and confirm
+// it's looking at the record it thinks it's looking at.
+type RecoveryHashReceiptBody struct {
+	Identity       string `json:"identity"`
+	RecoveryHash   string `json:"recoveryHash"`
+	Id             string `json:"id"`
+	SequenceNumber int64  `json:"sequenceNumber"`
+}
+
+// RecoveryHashReceipt is the signed body RecoveryHashStore.Current returns: Body is exactly
+// the bytes that were marshaled and signed, so a client can re-marshal Body and verify
+// Signature against it without the signed bytes ever having included the signature itself.
+type RecoveryHashReceipt struct {
+	Body      RecoveryHashReceiptBody `json:"body"`
+	Signature string                  `json:"signature"`
+}
+
+// RecoveryHashStore is the verifiable-storage-go-backed store.RecoveryHashStore implementation.
+type RecoveryHashStore struct {
+	recoveryHashRepository repository.Repository[*models.RecoveryHash]
+	hasher                 cryptointerfaces.Hasher
+	signer                 cryptointerfaces.SigningKey
+}
+
+// NewRecoveryHashStore builds a RecoveryHashStore. signer is used only by Current, to sign
+// the chain receipt it returns; it may be nil for deployments that never call Current.
+func NewRecoveryHashStore(store data.Store, signer cryptointerfaces.SigningKey) *RecoveryHashStore {
+	recoveryHashRepository := repository.NewVerifiableRepository[*models.RecoveryHash](store, true, true, nil)
+	return &RecoveryHashStore{
+		recoveryHashRepository: recoveryHashRepository,
+		hasher:                 crypto.NewBlake3(),
+		signer:                 signer,
+	}
+}
+
+func (s RecoveryHashStore) Register(ctx context.Context, identity, recoveryHash, commitment string) error {
+	record := &models.RecoveryHash{
+		Identity:     identity,
+		RecoveryHash: recoveryHash,
+		RotationHash: commitment,
+	}
+
+	// uniqueness constraint protects us from duplication
+	if err := s.recoveryHashRepository.CreateVersion(ctx, record); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Rotate replaces identity's recovery hash with newHash, the same pre-committed-hash
+// discipline AuthenticationKeyStore.Rotate enforces for device keys: newHash's hash must
+// match the commitment left by the previous Register/Rotate call, and commitment becomes
+// the value the rotation after this one must hash to.
+func (s RecoveryHashStore) Rotate(ctx context.Context, identity, newHash, commitment string) error {
+	record := &models.RecoveryHash{}
+	if err := s.recoveryHashRepository.Get(ctx, record, expressions.Equal("identity", identity), orderings.Descending("sequence_number")); err != nil {
+		return err
+	}
+
+	hash := s.hasher.Sum([]byte(newHash))
+
+	if !strings.EqualFold(hash, record.RotationHash) {
+		return fmt.Errorf("rotation hash does not match")
+	}
+
+	record.RecoveryHash = newHash
+	record.RotationHash = commitment
+
+	if err := s.recoveryHashRepository.CreateVersion(ctx, record); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s RecoveryHashStore) Change(ctx context.Context, identity, newHash string) error {
+	record := &models.RecoveryHash{}
+	if err := s.recoveryHashRepository.Get(ctx, record, expressions.Equal("identity", identity), orderings.Descending("sequence_number")); err != nil {
+		return err
+	}
+
+	record.RecoveryHash = newHash
+
+	if err := s.recoveryHashRepository.CreateVersion(ctx, record); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// chain returns every recorded version of identity's recovery hash, inception first.
+func (s RecoveryHashStore) chain(ctx context.Context, identity string) ([]*models.RecoveryHash, error) {
+	records := []*models.RecoveryHash{}
+
+	if err := s.recoveryHashRepository.ListLatestByPrefix(
+		ctx,
+		&records,
+		expressions.Equal("identity", identity),
+		nil,
+		orderings.Ascending("sequence_number"),
+		nil,
+	); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// VerifyChain walks identity's full recovery-hash chain from inception, checking each
+// record's SAID/address against the prior one and confirming the prior record's
+// RotationHash commitment matches this record's RecoveryHash, the same link-by-link
+// discipline already enforced one step at a time inside Rotate.
+//
+// This and Current are deliberately not on store.RecoveryHashStore: they replay the
+// verifiable-storage-go SAID chain this backend keeps, which pkg/store/memory and
+// pkg/store/badger don't have (their Rotate overwrites the current hash in place rather
+// than appending to a history), so there's nothing equivalent for those backends to
+// implement. cmd/better-auth-recovery-api serves both over HTTP against whichever backend a
+// deployment points it at, type-asserting for this wider method set and responding 501 against
+// a backend that doesn't have it.
+func (s RecoveryHashStore) VerifyChain(ctx context.Context, identity string) error {
+	records, err := s.chain(ctx, identity)
+	if err != nil {
+		return err
+	}
+
+	if len(records) == 0 {
+		return fmt.Errorf("no recovery hash chain for %s", identity)
+	}
+
+	if records[0].SequenceNumber != 0 {
+		return fmt.Errorf("chain for %s does not begin at sequence 0", identity)
+	}
+
+	for i, record := range records {
+		if record.SequenceNumber == 0 {
+			if err := algorithms.VerifyPrefixAndData(record); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := algorithms.VerifyAddressAndData(record); err != nil {
+			return err
+		}
+
+		previous := records[i-1]
+
+		if record.Previous == nil || *record.Previous != previous.Id {
+			return fmt.Errorf("broken chain at sequence %d for %s", record.SequenceNumber, identity)
+		}
+
+		if !strings.EqualFold(s.hasher.Sum([]byte(record.RecoveryHash)), previous.RotationHash) {
+			return fmt.Errorf("rotation hash commitment violated at sequence %d for %s", record.SequenceNumber, identity)
+		}
+	}
+
+	return nil
+}
+
+// Current returns identity's current recovery hash together with a RecoveryHashReceipt
+// signed by this store's signer, so a client can verify it offline against the server's
+// known recovery public key rather than trusting whatever transport handed it over.
+func (s RecoveryHashStore) Current(ctx context.Context, identity string) (*RecoveryHashReceipt, error) {
+	if s.signer == nil {
+		return nil, fmt.Errorf("recovery hash store has no signer configured")
+	}
+
+	record := &models.RecoveryHash{}
+	if err := s.recoveryHashRepository.Get(ctx, record, expressions.Equal("identity", identity), orderings.Descending("sequence_number")); err != nil {
+		return nil, err
+	}
+
+	body := RecoveryHashReceiptBody{
+		Identity:       record.Identity,
+		RecoveryHash:   record.RecoveryHash,
+		Id:             record.Id,
+		SequenceNumber: record.SequenceNumber,
+	}
+
+	message, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := s.signer.Sign(message)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecoveryHashReceipt{
+		Body:      body,
+		Signature: signature,
+	}, nil
+}
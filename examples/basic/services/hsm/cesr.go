@@ -2,10 +2,15 @@ package main
 
 import (
 	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"encoding/asn1"
 	"encoding/base64"
 	"fmt"
 	"math/big"
+	"strings"
+
+	"github.com/zeebo/blake3"
 )
 
 // CESR encoding utilities copied from better-auth-go
@@ -62,6 +67,27 @@ func PublicKeyToCESR(publicKey *ecdsa.PublicKey) (string, error) {
 	return cesrPublicKey, nil
 }
 
+// CESRToPublicKey is the inverse of PublicKeyToCESR: it decodes a "1AAI"-prefixed CESR
+// string back into an ECDSA P-256 public key.
+func CESRToPublicKey(cesrPublicKey string) (*ecdsa.PublicKey, error) {
+	if !strings.HasPrefix(cesrPublicKey, "1AAI") {
+		return nil, fmt.Errorf("unrecognized CESR public key prefix")
+	}
+
+	compressedKey, err := base64.URLEncoding.DecodeString(cesrPublicKey[4:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base64 public key: %w", err)
+	}
+
+	curve := elliptic.P256()
+	x, y := elliptic.UnmarshalCompressed(curve, compressedKey)
+	if x == nil {
+		return nil, fmt.Errorf("failed to decompress public key")
+	}
+
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
 // SignatureToCESR converts an ECDSA signature (R, S) to CESR format
 // Returns string with signature starting with "0I"
 func SignatureToCESR(r, s *big.Int) (string, error) {
@@ -82,6 +108,190 @@ func SignatureToCESR(r, s *big.Int) (string, error) {
 	return string(runes), nil
 }
 
+// secp256k1Curve returns the curve parameters for secp256k1, the algorithm Ethereum/Bitcoin
+// style identities use. crypto/elliptic doesn't ship it, so it's defined by hand from the
+// published SEC2 constants; the HSM never needs anything from it beyond point
+// (de)compression, so a CurveParams is sufficient and no third-party curve package is pulled in.
+func secp256k1Curve() *elliptic.CurveParams {
+	curve := &elliptic.CurveParams{Name: "secp256k1"}
+	curve.P, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f", 16)
+	curve.N, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", 16)
+	curve.B, _ = new(big.Int).SetString("0000000000000000000000000000000000000000000000000000000000000007", 16)
+	curve.Gx, _ = new(big.Int).SetString("79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", 16)
+	curve.Gy, _ = new(big.Int).SetString("483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8", 16)
+	curve.BitSize = 256
+	return curve
+}
+
+// curveFor returns the elliptic curve backing the given algorithm's ECDSA keys. Ed25519 has
+// no associated elliptic.Curve and is handled separately by its own CESR helpers.
+func curveFor(algorithm Algorithm) (elliptic.Curve, error) {
+	switch algorithm {
+	case AlgorithmP256:
+		return elliptic.P256(), nil
+	case AlgorithmSecp256k1:
+		return secp256k1Curve(), nil
+	default:
+		return nil, fmt.Errorf("algorithm %s has no associated elliptic curve", algorithm)
+	}
+}
+
+// cesrPrefixForPublicKey returns the CESR prefix used for a public key under the given
+// algorithm.
+func cesrPrefixForPublicKey(algorithm Algorithm) (string, error) {
+	switch algorithm {
+	case AlgorithmP256:
+		return "1AAI", nil
+	case AlgorithmSecp256k1:
+		return "1AAB", nil
+	case AlgorithmEd25519:
+		return "1AAD", nil
+	default:
+		return "", fmt.Errorf("unsupported algorithm: %s", algorithm)
+	}
+}
+
+// cesrPrefixForSignature returns the CESR prefix used for a signature under the given
+// algorithm.
+func cesrPrefixForSignature(algorithm Algorithm) (string, error) {
+	switch algorithm {
+	case AlgorithmP256:
+		return "0I", nil
+	case AlgorithmSecp256k1:
+		return "0C", nil
+	case AlgorithmEd25519:
+		return "0B", nil
+	default:
+		return "", fmt.Errorf("unsupported algorithm: %s", algorithm)
+	}
+}
+
+// ECPublicKeyToCESR converts an ECDSA public key on the curve associated with algorithm to
+// CESR format. It generalizes PublicKeyToCESR (which remains P-256-only, for existing
+// callers that only ever deal with P-256) to secp256k1 as well.
+func ECPublicKeyToCESR(publicKey *ecdsa.PublicKey, algorithm Algorithm) (string, error) {
+	prefix, err := cesrPrefixForPublicKey(algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	publicKeyBytes, err := publicKey.Bytes()
+	if err != nil {
+		return "", fmt.Errorf("failed to get public key bytes: %w", err)
+	}
+
+	compressedKey, err := compressPublicKey(publicKeyBytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to compress public key: %w", err)
+	}
+
+	return prefix + base64.URLEncoding.EncodeToString(compressedKey), nil
+}
+
+// Ed25519PublicKeyToCESR converts an Ed25519 public key to CESR format.
+func Ed25519PublicKeyToCESR(publicKey ed25519.PublicKey) (string, error) {
+	prefix, err := cesrPrefixForPublicKey(AlgorithmEd25519)
+	if err != nil {
+		return "", err
+	}
+
+	return prefix + base64.URLEncoding.EncodeToString(publicKey), nil
+}
+
+// CESRToAlgorithmPublicKey decodes a CESR-encoded public key of any supported algorithm,
+// dispatching on its prefix. It returns the decoded key (either *ecdsa.PublicKey or
+// ed25519.PublicKey) along with the algorithm it was encoded under, so CESRBlake3Sum and
+// rotation-hash comparisons that only ever operate on the CESR string itself are unaffected:
+// they never need to know which branch below produced it.
+func CESRToAlgorithmPublicKey(cesrPublicKey string) (any, Algorithm, error) {
+	switch {
+	case strings.HasPrefix(cesrPublicKey, "1AAI"):
+		publicKey, err := decodeECPublicKey(cesrPublicKey[4:], elliptic.P256())
+		return publicKey, AlgorithmP256, err
+	case strings.HasPrefix(cesrPublicKey, "1AAB"):
+		publicKey, err := decodeECPublicKey(cesrPublicKey[4:], secp256k1Curve())
+		return publicKey, AlgorithmSecp256k1, err
+	case strings.HasPrefix(cesrPublicKey, "1AAD"):
+		publicKey, err := decodeEd25519PublicKey(cesrPublicKey[4:])
+		return publicKey, AlgorithmEd25519, err
+	default:
+		return nil, "", fmt.Errorf("unrecognized CESR public key prefix")
+	}
+}
+
+func decodeECPublicKey(encoded string, curve elliptic.Curve) (*ecdsa.PublicKey, error) {
+	compressedKey, err := base64.URLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base64 public key: %w", err)
+	}
+
+	x, y := elliptic.UnmarshalCompressed(curve, compressedKey)
+	if x == nil {
+		return nil, fmt.Errorf("failed to decompress public key")
+	}
+
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+func decodeEd25519PublicKey(encoded string) (ed25519.PublicKey, error) {
+	keyBytes, err := base64.URLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base64 public key: %w", err)
+	}
+
+	if len(keyBytes) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid Ed25519 public key length: expected %d, got %d", ed25519.PublicKeySize, len(keyBytes))
+	}
+
+	return ed25519.PublicKey(keyBytes), nil
+}
+
+// Ed25519SignatureToCESR converts a raw 64-byte Ed25519 signature to CESR format.
+func Ed25519SignatureToCESR(signature []byte) (string, error) {
+	if len(signature) != ed25519.SignatureSize {
+		return "", fmt.Errorf("invalid Ed25519 signature length: expected %d, got %d", ed25519.SignatureSize, len(signature))
+	}
+
+	prefix, err := cesrPrefixForSignature(AlgorithmEd25519)
+	if err != nil {
+		return "", err
+	}
+
+	return prefix + base64.URLEncoding.EncodeToString(signature), nil
+}
+
+// ECSignatureToCESR converts an ECDSA signature (R, S) to CESR format under the given
+// algorithm's signature prefix, generalizing SignatureToCESR (which remains hard-coded to the
+// "0I" P-256 prefix for existing callers) to secp256k1.
+func ECSignatureToCESR(r, s *big.Int, algorithm Algorithm) (string, error) {
+	prefix, err := cesrPrefixForSignature(algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	signatureBytes := make([]byte, 66)
+	r.FillBytes(signatureBytes[2:34])
+	s.FillBytes(signatureBytes[34:66])
+
+	base64Signature := base64.URLEncoding.EncodeToString(signatureBytes)
+	runes := []rune(base64Signature)
+	runes[0] = rune(prefix[0])
+	runes[1] = rune(prefix[1])
+
+	return string(runes), nil
+}
+
+// CESRBlake3Sum computes the rotation-hash commitment for a CESR-encoded public key: a Blake3
+// digest of the CESR string itself, base64url-encoded. A CESR public key self-describes its
+// algorithm via its prefix ("1AAI", "1AAB", "1AAD", ...), so this works uniformly across every
+// algorithm the HSM supports without needing to branch on one; mirrors
+// pkg/implementation/migrate.rotationHashOf, which computes the same commitment from the
+// migration tool's side.
+func CESRBlake3Sum(cesrPublicKey string) string {
+	sum := blake3.Sum256([]byte(cesrPublicKey))
+	return base64.URLEncoding.EncodeToString(sum[:])
+}
+
 // ParseASN1Signature parses an ASN.1 DER encoded ECDSA signature
 // Returns R and S components
 func ParseASN1Signature(asn1Sig []byte) (*big.Int, *big.Int, error) {
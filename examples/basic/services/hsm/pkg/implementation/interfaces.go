@@ -0,0 +1,49 @@
+// Package implementation declares the persistence interfaces HSMServer needs, independent
+// of any particular backend, so a deployment can swap the Postgres+Redis defaults for
+// BoltDB, DynamoDB, Consul, an in-memory store for tests, or anything else that can satisfy
+// these two small contracts.
+package implementation
+
+import "context"
+
+// HSMKeyRecordStore persists the HSM's own signing-key lineage (the "keys" table), so
+// NewHSMServerFromConfig can discover the active key generation across restarts and
+// rotateSigningKey can append the next one.
+type HSMKeyRecordStore interface {
+	// Latest returns the most recent key record for PURPOSE, or nil if none exists yet.
+	Latest(ctx context.Context) (*Keys, error)
+	Append(ctx context.Context, record *Keys) error
+	// History returns every key record for PURPOSE in ascending sequence-number order, for
+	// tooling (e.g. pkg/implementation/migrate) that needs to walk or copy the full rotation
+	// chain rather than just its head.
+	History(ctx context.Context) ([]*Keys, error)
+}
+
+// RotationPublisher announces a freshly (re)generated HSM key, signed, to whatever other
+// services need to learn about it. A Redis SET keyed by the record's id (redisadapter's
+// SetRotationPublisher) is one adapter; others push the event rather than just parking it,
+// so a verifier can react immediately instead of polling.
+type RotationPublisher interface {
+	Publish(ctx context.Context, record *Keys, signature string) error
+}
+
+// RotationEvent is one published rotation, as delivered to a RotationSubscriber.
+type RotationEvent struct {
+	Payload   *Keys
+	Signature string
+	// Offset identifies this event's position in the underlying transport's replay log (a
+	// Redis Streams ID, a NATS JetStream sequence number), or "" for transports with no such
+	// concept (e.g. plain Redis Pub/Sub). A handler that persists Offset can pass it back as
+	// Subscribe's resume argument after a restart to pick up where it left off.
+	Offset string
+}
+
+// RotationSubscriber lets an AccessKeyHashStore-side verifier learn about new rotations as
+// they happen instead of polling RotationPublisher's backing store for changes.
+type RotationSubscriber interface {
+	// Subscribe delivers every RotationEvent published for purpose to handler, starting after
+	// resume (pass "" to start from only newly published events — the correct choice for
+	// transports, like plain Pub/Sub, that can't replay). Subscribe blocks until ctx is
+	// cancelled or handler returns a non-nil error, which Subscribe then returns.
+	Subscribe(ctx context.Context, purpose string, resume string, handler func(RotationEvent) error) error
+}
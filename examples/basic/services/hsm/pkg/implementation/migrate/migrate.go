@@ -0,0 +1,97 @@
+// Package migrate streams an HSMKeyRecordStore's rotation chain into another
+// HSMKeyRecordStore, re-verifying the RotationHash commitment between consecutive records as
+// it goes so a Postgres-to-Postgres reshard, a Postgres-to-SQLite move, or any other
+// backend-to-backend copy can't silently carry forward a corrupted chain.
+package migrate
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/jasoncolburne/better-auth/examples/basic/services/hsm/pkg/implementation"
+	"github.com/zeebo/blake3"
+)
+
+// Cursor identifies a resume point in the key-record chain, keyed on the fields Run orders
+// by: (purpose, sequence_number). Passing the Cursor from a partial Run back in via
+// Options.Resume skips everything at or before it.
+type Cursor struct {
+	Purpose        string
+	SequenceNumber uint64
+}
+
+// Options configures a single Run.
+type Options struct {
+	// DryRun verifies the chain and reports what would be written without calling
+	// dest.Append.
+	DryRun bool
+	// VerifyOnly walks the source chain end-to-end without touching dest at all. Implies
+	// DryRun.
+	VerifyOnly bool
+	// Resume, if set, skips every record at or before this cursor.
+	Resume *Cursor
+}
+
+// Result summarizes a completed or partial Run.
+type Result struct {
+	Cursor   Cursor
+	Verified int
+	Migrated int
+}
+
+// Run streams source's rotation chain in ascending sequence-number order, verifying each
+// record's id/previous continuity and RotationHash commitment against the record before it,
+// then appends it to dest unless opts.DryRun or opts.VerifyOnly is set. It returns a Cursor
+// that a later Run can pass back via opts.Resume to continue where this one left off.
+func Run(ctx context.Context, source, dest implementation.HSMKeyRecordStore, opts Options) (Result, error) {
+	records, err := source.History(ctx)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to read source history: %w", err)
+	}
+
+	var result Result
+	var lastId, lastRotationHash string
+	resuming := opts.Resume != nil
+
+	for _, record := range records {
+		if record.Previous != nil {
+			if lastId == "" || *record.Previous != lastId {
+				return result, fmt.Errorf("broken chain at sequence %d", record.SequenceNumber)
+			}
+
+			if !strings.EqualFold(rotationHashOf(record.PublicKey), lastRotationHash) {
+				return result, fmt.Errorf("bad rotation-hash commitment at sequence %d", record.SequenceNumber)
+			}
+		}
+
+		lastId = record.Id
+		lastRotationHash = record.RotationHash
+		result.Verified++
+		result.Cursor = Cursor{Purpose: record.Purpose, SequenceNumber: record.SequenceNumber}
+
+		if resuming && record.Purpose == opts.Resume.Purpose && record.SequenceNumber <= opts.Resume.SequenceNumber {
+			continue
+		}
+		resuming = false
+
+		if opts.VerifyOnly || opts.DryRun {
+			continue
+		}
+
+		if err := dest.Append(ctx, record); err != nil {
+			return result, fmt.Errorf("failed to write sequence %d to destination: %w", record.SequenceNumber, err)
+		}
+		result.Migrated++
+	}
+
+	return result, nil
+}
+
+// rotationHashOf mirrors the rotation-hash commitment scheme the HSM uses when it rotates
+// keys: a Blake3 digest of the CESR-encoded public key, base64url-encoded.
+func rotationHashOf(cesrPublicKey string) string {
+	sum := blake3.Sum256([]byte(cesrPublicKey))
+	return base64.URLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,96 @@
+package implementation
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/primitives"
+)
+
+const PURPOSE = "key-authorization"
+
+// this table omits a nonce for more determinism
+const KEYS_TABLE_SQL = `
+	CREATE TABLE IF NOT EXISTS keys (
+		-- Standard fields
+		id              	TEXT PRIMARY KEY,
+		prefix				TEXT NOT NULL,
+		previous        	TEXT,
+		sequence_number 	BIGINT NOT NULL,
+
+		-- Optional fields
+		created_at          TIMESTAMP NOT NULL,
+
+		-- Model-specific fields
+		purpose             TEXT NOT NULL,
+		public_key			TEXT NOT NULL,
+		rotation_hash       TEXT NOT NULL,
+		algorithm           TEXT NOT NULL DEFAULT 'P256',
+		shares              TEXT,
+
+		-- Uniqueness constraint for sequence numbers
+		UNIQUE(prefix, sequence_number)
+	);
+`
+
+// ShareDescriptor identifies one peer HSM's contribution to a quorum-signed key: which peer
+// holds the share, and a commitment to it (the peer's local public-key share point) that lets
+// a reader confirm the aggregate PublicKey was actually assembled from these peers without
+// learning any peer's private share.
+type ShareDescriptor struct {
+	PeerIdentity    string `json:"peerIdentity"`
+	ShareCommitment string `json:"shareCommitment"`
+}
+
+// ShareDescriptors is a []ShareDescriptor that knows how to store itself in the keys table's
+// single `shares` TEXT column as JSON, since verifiable-storage-go has no native array type.
+type ShareDescriptors []ShareDescriptor
+
+func (s ShareDescriptors) Value() (driver.Value, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+
+	bytes, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(bytes), nil
+}
+
+func (s *ShareDescriptors) Scan(value any) error {
+	if value == nil {
+		*s = nil
+		return nil
+	}
+
+	switch v := value.(type) {
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	case []byte:
+		return json.Unmarshal(v, s)
+	default:
+		return fmt.Errorf("unsupported type for ShareDescriptors: %T", value)
+	}
+}
+
+type Keys struct {
+	primitives.VerifiableRecorder
+	Purpose      string `db:"purpose" json:"purpose"`
+	PublicKey    string `db:"public_key" json:"publicKey"`
+	RotationHash string `db:"rotation_hash" json:"rotationHash"`
+	// Algorithm records which signature scheme PublicKey was generated under (e.g. "P256",
+	// "Ed25519", "Secp256k1"), so rotateSigningKey can tell whether a proposed next key
+	// would cross an algorithm boundary. Defaults to "P256" for records written before this
+	// field existed.
+	Algorithm string `db:"algorithm" json:"algorithm"`
+	// Shares is only populated for a quorum-signed identity (see QuorumSigner); it's empty
+	// for a single-HSM identity.
+	Shares ShareDescriptors `db:"shares" json:"shares,omitempty"`
+}
+
+func (*Keys) TableName() string {
+	return "keys"
+}
@@ -0,0 +1,139 @@
+// Package natsadapter is an implementation.RotationPublisher/RotationSubscriber backed by
+// NATS JetStream, for deployments that already run NATS rather than Redis for messaging.
+// JetStream durable consumers give the same "replay what I missed" guarantee as
+// redisadapter's StreamRotationPublisher/StreamRotationSubscriber.
+package natsadapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/jasoncolburne/better-auth/examples/basic/services/hsm/pkg/implementation"
+	"github.com/nats-io/nats.go"
+)
+
+// rotationSubject is the JetStream subject a purpose's rotations are published on.
+func rotationSubject(purpose string) string {
+	return "rotations." + purpose
+}
+
+type rotationMessage struct {
+	Payload   *implementation.Keys `json:"payload"`
+	Signature string               `json:"signature"`
+}
+
+// RotationPublisher publishes each rotation to a JetStream stream, configured from the
+// environment (NATS_URL, defaulting to the in-cluster service name).
+type RotationPublisher struct {
+	js nats.JetStreamContext
+}
+
+func NewRotationPublisher() (*RotationPublisher, error) {
+	js, err := connectJetStream()
+	if err != nil {
+		return nil, err
+	}
+
+	return &RotationPublisher{js: js}, nil
+}
+
+func (p *RotationPublisher) Publish(ctx context.Context, record *implementation.Keys, signature string) error {
+	messageJson, err := json.Marshal(rotationMessage{Payload: record, Signature: signature})
+	if err != nil {
+		return err
+	}
+
+	_, err = p.js.Publish(rotationSubject(record.Purpose), messageJson, nats.Context(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to publish rotation to JetStream: %w", err)
+	}
+
+	return nil
+}
+
+// RotationSubscriber is the RotationSubscriber counterpart to RotationPublisher, reading
+// through a durable JetStream consumer so a restarting verifier can replay missed rotations.
+type RotationSubscriber struct {
+	js          nats.JetStreamContext
+	durableName string
+}
+
+func NewRotationSubscriber(durableName string) (*RotationSubscriber, error) {
+	js, err := connectJetStream()
+	if err != nil {
+		return nil, err
+	}
+
+	return &RotationSubscriber{js: js, durableName: durableName}, nil
+}
+
+func (s *RotationSubscriber) Subscribe(ctx context.Context, purpose string, resume string, handler func(implementation.RotationEvent) error) error {
+	subject := rotationSubject(purpose)
+
+	opts := []nats.SubOpt{nats.Durable(s.durableName), nats.ManualAck()}
+	if resume != "" {
+		if startSeq, err := strconv.ParseUint(resume, 10, 64); err == nil {
+			opts = append(opts, nats.StartSequence(startSeq))
+		}
+	} else {
+		opts = append(opts, nats.DeliverNew())
+	}
+
+	sub, err := s.js.SubscribeSync(subject, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to JetStream subject %s: %w", subject, err)
+	}
+	defer sub.Unsubscribe()
+
+	for {
+		msg, err := sub.NextMsgWithContext(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("failed to read rotation message: %w", err)
+		}
+
+		var message rotationMessage
+		if err := json.Unmarshal(msg.Data, &message); err != nil {
+			return err
+		}
+
+		metadata, err := msg.Metadata()
+		offset := ""
+		if err == nil {
+			offset = strconv.FormatUint(metadata.Sequence.Stream, 10)
+		}
+
+		event := implementation.RotationEvent{Payload: message.Payload, Signature: message.Signature, Offset: offset}
+		if err := handler(event); err != nil {
+			return err
+		}
+
+		if err := msg.Ack(); err != nil {
+			return fmt.Errorf("failed to ack rotation message: %w", err)
+		}
+	}
+}
+
+func connectJetStream() (nats.JetStreamContext, error) {
+	natsUrl := os.Getenv("NATS_URL")
+	if natsUrl == "" {
+		natsUrl = nats.DefaultURL
+	}
+
+	nc, err := nats.Connect(natsUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	js, err := nc.JetStream()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get JetStream context: %w", err)
+	}
+
+	return js, nil
+}
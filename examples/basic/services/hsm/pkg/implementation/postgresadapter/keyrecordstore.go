@@ -0,0 +1,63 @@
+// Package postgresadapter is the default implementation.HSMKeyRecordStore, backed by
+// verifiable-storage-go over Postgres.
+package postgresadapter
+
+import (
+	"context"
+
+	"github.com/jasoncolburne/better-auth/examples/basic/services/hsm/pkg/implementation"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/expressions"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/orderings"
+	"github.com/jasoncolburne/verifiable-storage-go/pkg/repository"
+)
+
+type KeyRecordStore struct {
+	repository repository.Repository[*implementation.Keys]
+}
+
+func NewKeyRecordStore(store data.Store) *KeyRecordStore {
+	return &KeyRecordStore{
+		repository: repository.NewVerifiableRepository[*implementation.Keys](store, true, true, nil),
+	}
+}
+
+func (s KeyRecordStore) Latest(ctx context.Context) (*implementation.Keys, error) {
+	records := []*implementation.Keys{}
+
+	if err := s.repository.Select(
+		ctx,
+		&records,
+		expressions.Equal("purpose", implementation.PURPOSE),
+		orderings.Descending("sequence_number"),
+		nil,
+	); err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	return records[0], nil
+}
+
+func (s KeyRecordStore) Append(ctx context.Context, record *implementation.Keys) error {
+	return s.repository.CreateVersion(ctx, record)
+}
+
+func (s KeyRecordStore) History(ctx context.Context) ([]*implementation.Keys, error) {
+	records := []*implementation.Keys{}
+
+	if err := s.repository.Select(
+		ctx,
+		&records,
+		expressions.Equal("purpose", implementation.PURPOSE),
+		orderings.Ascending("sequence_number"),
+		nil,
+	); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
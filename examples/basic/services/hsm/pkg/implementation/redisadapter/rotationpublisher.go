@@ -0,0 +1,110 @@
+// Package redisadapter is the default implementation.RotationPublisher/RotationSubscriber,
+// backed by Redis. It ships three adapters — a plain SET (the original, simplest behavior),
+// Pub/Sub, and Streams with consumer-group acking — selected via ROTATION_PUBLISHER_MODE so
+// a deployment can pick the replay/ack guarantees it needs without code changes.
+package redisadapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jasoncolburne/better-auth/examples/basic/services/hsm/pkg/implementation"
+	"github.com/redis/go-redis/v9"
+)
+
+// NewRotationPublisher builds the RotationPublisher selected by ROTATION_PUBLISHER_MODE
+// ("set" (default, preserving pre-existing behavior), "pubsub", or "stream"), configured
+// from the environment.
+func NewRotationPublisher() (implementation.RotationPublisher, error) {
+	client, err := newRedisClient()
+	if err != nil {
+		return nil, err
+	}
+
+	switch mode := os.Getenv("ROTATION_PUBLISHER_MODE"); mode {
+	case "", "set":
+		return &SetRotationPublisher{client: client}, nil
+	case "pubsub":
+		return &PubSubRotationPublisher{client: client}, nil
+	case "stream":
+		return &StreamRotationPublisher{client: client}, nil
+	default:
+		return nil, fmt.Errorf("unrecognized ROTATION_PUBLISHER_MODE: %s", mode)
+	}
+}
+
+// rotationMessage is the wire shape published (or SET) by every adapter in this package.
+type rotationMessage struct {
+	Payload   *implementation.Keys `json:"payload"`
+	Signature string               `json:"signature"`
+}
+
+// SetRotationPublisher is the original adapter: it parks the latest signed record at a Redis
+// key named after the record's id, for verifiers that poll rather than subscribe.
+type SetRotationPublisher struct {
+	client *redis.Client
+}
+
+func (p *SetRotationPublisher) Publish(ctx context.Context, record *implementation.Keys, signature string) error {
+	messageJson, err := json.Marshal(rotationMessage{Payload: record, Signature: signature})
+	if err != nil {
+		return err
+	}
+
+	// Retry Redis Set operation to handle connection drops gracefully
+	_, err = retryRedisOperation(ctx, func() (struct{}, error) {
+		return struct{}{}, p.client.Set(ctx, record.Id, messageJson, 0).Err()
+	})
+
+	return err
+}
+
+// retryRedisOperation executes a Redis operation with retry logic and exponential backoff.
+// This ensures graceful recovery when Redis restarts or connections drop.
+func retryRedisOperation[T any](ctx context.Context, operation func() (T, error)) (T, error) {
+	const maxRetries = 3
+	const initialBackoff = 100 * time.Millisecond
+
+	var lastErr error
+	var zero T
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		if attempt > 0 {
+			// Exponential backoff: 100ms, 200ms, 400ms
+			backoff := initialBackoff * time.Duration(1<<uint(attempt-1))
+			time.Sleep(backoff)
+		}
+
+		result, err := operation()
+		if err != nil {
+			lastErr = err
+			continue // Retry
+		}
+
+		return result, nil
+	}
+
+	return zero, fmt.Errorf("redis operation failed after %d retries: %w", maxRetries, lastErr)
+}
+
+func newRedisClient() (*redis.Client, error) {
+	redisHost := os.Getenv("REDIS_HOST")
+	if redisHost == "" {
+		redisHost = "redis:6379"
+	}
+
+	redisDbHsmKeysString := os.Getenv("REDIS_DB_HSM_KEYS")
+	redisDbHsmKeys, err := strconv.Atoi(redisDbHsmKeysString)
+	if err != nil {
+		return nil, err
+	}
+
+	return redis.NewClient(&redis.Options{
+		Addr: redisHost,
+		DB:   redisDbHsmKeys,
+	}), nil
+}
@@ -0,0 +1,189 @@
+package redisadapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jasoncolburne/better-auth/examples/basic/services/hsm/pkg/implementation"
+	"github.com/redis/go-redis/v9"
+)
+
+// rotationChannel is the Redis Pub/Sub channel / Streams key a purpose's rotations are
+// published on.
+func rotationChannel(purpose string) string {
+	return "rotations:" + purpose
+}
+
+// PubSubRotationPublisher publishes each rotation on a Redis Pub/Sub channel named after the
+// record's purpose, for verifiers that want to update their cached HSM public key immediately
+// rather than polling a SET key. Pub/Sub has no replay: a subscriber that's down when a
+// rotation is published simply misses it, so pair this with the existing SetRotationPublisher
+// (or switch to StreamRotationPublisher) if missed rotations must still be recoverable.
+type PubSubRotationPublisher struct {
+	client *redis.Client
+}
+
+func (p *PubSubRotationPublisher) Publish(ctx context.Context, record *implementation.Keys, signature string) error {
+	messageJson, err := json.Marshal(rotationMessage{Payload: record, Signature: signature})
+	if err != nil {
+		return err
+	}
+
+	_, err = retryRedisOperation(ctx, func() (struct{}, error) {
+		return struct{}{}, p.client.Publish(ctx, rotationChannel(record.Purpose), messageJson).Err()
+	})
+
+	return err
+}
+
+// PubSubRotationSubscriber is the RotationSubscriber counterpart to PubSubRotationPublisher.
+// resume is ignored (a no-op) since Pub/Sub has no offset to resume from.
+type PubSubRotationSubscriber struct {
+	client *redis.Client
+}
+
+func NewPubSubRotationSubscriber() (*PubSubRotationSubscriber, error) {
+	client, err := newRedisClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return &PubSubRotationSubscriber{client: client}, nil
+}
+
+func (s *PubSubRotationSubscriber) Subscribe(ctx context.Context, purpose string, resume string, handler func(implementation.RotationEvent) error) error {
+	pubsub := s.client.Subscribe(ctx, rotationChannel(purpose))
+	defer pubsub.Close()
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-ch:
+			if !ok {
+				return fmt.Errorf("rotation pub/sub channel closed")
+			}
+
+			var message rotationMessage
+			if err := json.Unmarshal([]byte(msg.Payload), &message); err != nil {
+				return err
+			}
+
+			event := implementation.RotationEvent{Payload: message.Payload, Signature: message.Signature}
+			if err := handler(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// StreamRotationPublisher publishes each rotation as an entry on a Redis Stream named after
+// the record's purpose. Unlike Pub/Sub, Streams retain entries, so a restarting verifier can
+// use a consumer group to replay anything it missed.
+type StreamRotationPublisher struct {
+	client *redis.Client
+}
+
+func (p *StreamRotationPublisher) Publish(ctx context.Context, record *implementation.Keys, signature string) error {
+	messageJson, err := json.Marshal(rotationMessage{Payload: record, Signature: signature})
+	if err != nil {
+		return err
+	}
+
+	_, err = retryRedisOperation(ctx, func() (struct{}, error) {
+		return struct{}{}, p.client.XAdd(ctx, &redis.XAddArgs{
+			Stream: rotationChannel(record.Purpose),
+			Values: map[string]any{"message": messageJson},
+		}).Err()
+	})
+
+	return err
+}
+
+// StreamRotationSubscriber is the RotationSubscriber counterpart to StreamRotationPublisher.
+// It reads through a consumer group so a restarting verifier can pass resume (the last
+// Offset it successfully handled) to replay anything published while it was down, rather
+// than resuming only from newly published entries.
+type StreamRotationSubscriber struct {
+	client *redis.Client
+	group  string
+	// consumer distinguishes this subscriber instance within group; callers running multiple
+	// replicas should give each a unique consumer name so Redis load-balances stream entries
+	// across them rather than every replica reading every entry.
+	consumer string
+}
+
+func NewStreamRotationSubscriber(group string, consumer string) (*StreamRotationSubscriber, error) {
+	client, err := newRedisClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return &StreamRotationSubscriber{client: client, group: group, consumer: consumer}, nil
+}
+
+func (s *StreamRotationSubscriber) Subscribe(ctx context.Context, purpose string, resume string, handler func(implementation.RotationEvent) error) error {
+	stream := rotationChannel(purpose)
+
+	startId := "0"
+	if resume != "" {
+		startId = resume
+	}
+
+	if err := s.client.XGroupCreateMkStream(ctx, stream, s.group, startId).Err(); err != nil && !isBusyGroupErr(err) {
+		return fmt.Errorf("failed to create consumer group: %w", err)
+	}
+
+	// Replay anything already in the group's pending entries list (delivered but never
+	// acked — e.g. the previous process crashed mid-handler) before moving on to new entries.
+	for _, readId := range []string{"0", ">"} {
+		for {
+			results, err := s.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+				Group:    s.group,
+				Consumer: s.consumer,
+				Streams:  []string{stream, readId},
+				Count:    100,
+				Block:    0,
+			}).Result()
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				return fmt.Errorf("failed to read rotation stream: %w", err)
+			}
+
+			if len(results) == 0 || len(results[0].Messages) == 0 {
+				break
+			}
+
+			for _, entry := range results[0].Messages {
+				messageJson, ok := entry.Values["message"].(string)
+				if !ok {
+					return fmt.Errorf("rotation stream entry %s missing message field", entry.ID)
+				}
+
+				var message rotationMessage
+				if err := json.Unmarshal([]byte(messageJson), &message); err != nil {
+					return err
+				}
+
+				event := implementation.RotationEvent{Payload: message.Payload, Signature: message.Signature, Offset: entry.ID}
+				if err := handler(event); err != nil {
+					return err
+				}
+
+				if err := s.client.XAck(ctx, stream, s.group, entry.ID).Err(); err != nil {
+					return fmt.Errorf("failed to ack rotation entry %s: %w", entry.ID, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func isBusyGroupErr(err error) bool {
+	return err != nil && err.Error() == "BUSYGROUP Consumer Group name already exists"
+}
@@ -0,0 +1,544 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jasoncolburne/better-auth/examples/basic/services/hsm/pkg/implementation"
+)
+
+// QuorumSigner lets a single logical signing identity be backed by n peer HSMs, each holding a
+// Shamir share of the P-256 private scalar, so a single CESR signature under the aggregate
+// public key can be assembled from any m of those n peers' partial signatures — tolerating up
+// to n-m peers being unreachable — without any one peer ever holding the full private key for
+// longer than the moment it's dealt during Rotate (see Rotate's doc comment).
+//
+// Every peer — including the one acting as coordinator for a given request — derives the same
+// per-signature nonce deterministically from HMAC(aggregatePublicKey, H(msg)), so the partial
+// signatures share a common (r, R) without a coordination round. Each peer signs the full
+// message digest against its own share; the coordinator combines whichever m raw partials
+// actually arrived using the Lagrange coefficients for that specific subset (see
+// lagrangeCoefficientAtZero), which is what makes the combination correct for an arbitrary
+// m-of-n subset rather than only a fixed one.
+type QuorumSigner struct {
+	peers      []string // peer base URLs, index-aligned with peerShareIndex
+	m          int
+	shareIndex int      // this peer's own index into peers
+	share      *big.Int // this peer's local Shamir share f(shareIndex+1) of the aggregate private key
+	publicKey  *ecdsa.PublicKey
+	client     *http.Client
+
+	pendingMu    sync.Mutex
+	pendingShare *big.Int // set by PrepareRotation, consumed by CommitRotation
+}
+
+// NewQuorumSigner configures a coordinator/participant for an (m, len(peers)) quorum. share is
+// this peer's local Shamir share of the aggregate private key, evaluated at x = shareIndex+1;
+// publicKey is the aggregate public key the quorum collectively signs under.
+func NewQuorumSigner(peers []string, m int, shareIndex int, share *big.Int, publicKey *ecdsa.PublicKey) (*QuorumSigner, error) {
+	if m < 1 || m > len(peers) {
+		return nil, fmt.Errorf("invalid quorum policy: m=%d n=%d", m, len(peers))
+	}
+
+	if shareIndex < 0 || shareIndex >= len(peers) {
+		return nil, fmt.Errorf("invalid share index %d for %d peers", shareIndex, len(peers))
+	}
+
+	return &QuorumSigner{
+		peers:      peers,
+		m:          m,
+		shareIndex: shareIndex,
+		share:      share,
+		publicKey:  publicKey,
+		client:     &http.Client{Timeout: 5 * time.Second},
+	}, nil
+}
+
+// peerXCoordinate returns the Shamir x-coordinate assigned to the peer at index i in peers.
+// x=0 is reserved for the secret itself (f(0)), so coordinates start at 1.
+func peerXCoordinate(i int) *big.Int {
+	return big.NewInt(int64(i) + 1)
+}
+
+// lagrangeCoefficientAtZero computes L_i(0) for the Lagrange basis polynomial that is 1 at
+// xs[i] and 0 at every other xs[j], over the scalar field of order n. Summing
+// lagrangeCoefficientAtZero(xs, i, n) * f(xs[i]) over all i reconstructs f(0) for any degree
+// < len(xs) polynomial f — the standard Shamir reconstruction formula.
+func lagrangeCoefficientAtZero(xs []*big.Int, i int, n *big.Int) (*big.Int, error) {
+	xi := xs[i]
+
+	num := big.NewInt(1)
+	den := big.NewInt(1)
+
+	for j, xj := range xs {
+		if j == i {
+			continue
+		}
+
+		negXj := new(big.Int).Neg(xj)
+		negXj.Mod(negXj, n)
+		num.Mul(num, negXj)
+		num.Mod(num, n)
+
+		diff := new(big.Int).Sub(xi, xj)
+		diff.Mod(diff, n)
+		den.Mul(den, diff)
+		den.Mod(den, n)
+	}
+
+	denInverse := new(big.Int).ModInverse(den, n)
+	if denInverse == nil {
+		return nil, fmt.Errorf("degenerate lagrange denominator for duplicate peer coordinates")
+	}
+
+	coefficient := new(big.Int).Mul(num, denInverse)
+	coefficient.Mod(coefficient, n)
+
+	return coefficient, nil
+}
+
+// generateShamirShares builds a random degree-(m-1) polynomial over the curve's scalar field
+// with f(0) = secret, and returns its evaluation at x = 1..n, one point per peer index. Any m
+// of the n returned shares determine the other coefficients and reconstruct secret via
+// lagrangeCoefficientAtZero; fewer than m reveal nothing about it.
+func generateShamirShares(secret *big.Int, m, n int, curve elliptic.Curve) ([]*big.Int, error) {
+	order := curve.Params().N
+
+	coefficients := make([]*big.Int, m)
+	coefficients[0] = secret
+	for i := 1; i < m; i++ {
+		c, err := rand.Int(rand.Reader, order)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate polynomial coefficient: %w", err)
+		}
+		coefficients[i] = c
+	}
+
+	shares := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		shares[i] = evalPolynomial(coefficients, peerXCoordinate(i), order)
+	}
+
+	return shares, nil
+}
+
+// evalPolynomial evaluates the polynomial with the given coefficients (lowest degree first) at
+// x, mod order.
+func evalPolynomial(coefficients []*big.Int, x, order *big.Int) *big.Int {
+	result := new(big.Int)
+	power := big.NewInt(1)
+
+	for _, c := range coefficients {
+		term := new(big.Int).Mul(c, power)
+		result.Add(result, term)
+		result.Mod(result, order)
+
+		power.Mul(power, x)
+		power.Mod(power, order)
+	}
+
+	return result
+}
+
+type quorumPartialSignRequest struct {
+	Message []byte `json:"message"`
+}
+
+type quorumPartialSignResponse struct {
+	PeerIdentity string `json:"peerIdentity"`
+	S            string `json:"s"` // hex-encoded raw partial s_i, not yet Lagrange-weighted
+}
+
+// nonceAndDigest derives the message digest and the deterministic per-signature nonce every
+// peer computes independently from public information (the aggregate public key and the
+// message digest), so no coordination round is needed to agree on a common R.
+func (q *QuorumSigner) nonceAndDigest(curve elliptic.Curve, message []byte) (z, k *big.Int) {
+	n := curve.Params().N
+
+	digest := sha256.Sum256(message)
+	z = new(big.Int).SetBytes(digest[:])
+	z.Mod(z, n)
+
+	mac := hmac.New(sha256.New, elliptic.Marshal(curve, q.publicKey.X, q.publicKey.Y))
+	mac.Write(digest[:])
+	k = new(big.Int).SetBytes(mac.Sum(nil))
+	k.Mod(k, n)
+
+	return z, k
+}
+
+// partialSign computes this peer's raw contribution s_i = k^-1 * (z + r*d_i) mod N to a quorum
+// signature over message, using only its local share d_i. It deliberately does not apply a
+// Lagrange weight: the coordinator doesn't know which subset of peers will end up contributing
+// until Sign has collected m responses, so each peer signs independently of that subset and the
+// coordinator applies the subset-specific weights once it combines the raw partials (see Sign).
+func (q *QuorumSigner) partialSign(message []byte) (*big.Int, error) {
+	curve := elliptic.P256()
+	n := curve.Params().N
+
+	z, k := q.nonceAndDigest(curve, message)
+
+	rx, _ := curve.ScalarBaseMult(k.Bytes())
+	r := new(big.Int).Mod(rx, n)
+	if r.Sign() == 0 {
+		return nil, fmt.Errorf("degenerate nonce produced r=0")
+	}
+
+	kInverse := new(big.Int).ModInverse(k, n)
+	if kInverse == nil {
+		return nil, fmt.Errorf("degenerate nonce has no inverse mod curve order")
+	}
+
+	s := new(big.Int).Mul(r, q.share)
+	s.Add(s, z)
+	s.Mul(s, kInverse)
+	s.Mod(s, n)
+
+	return s, nil
+}
+
+// Sign fans the message out to this quorum's peers, collects at least m raw partial signatures
+// (this peer's own share counts as one), combines them with the Lagrange coefficients for the
+// specific subset that responded, and returns one CESR signature under the aggregate public
+// key. Because sum_i lagrangeCoefficientAtZero(i) == 1 for any size-m subset, and each partial
+// s_i = k^-1*(z + r*d_i), the Lagrange-weighted sum telescopes to k^-1*(z + r*d) for the full
+// aggregate secret d — exactly a standard ECDSA signature, without any peer ever combining
+// shares into d itself.
+func (q *QuorumSigner) Sign(ctx context.Context, message []byte) (string, error) {
+	curve := elliptic.P256()
+	n := curve.Params().N
+
+	_, k := q.nonceAndDigest(curve, message)
+	rx, _ := curve.ScalarBaseMult(k.Bytes())
+	r := new(big.Int).Mod(rx, n)
+
+	localRaw, err := q.partialSign(message)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute local partial signature: %w", err)
+	}
+
+	xs := []*big.Int{peerXCoordinate(q.shareIndex)}
+	raws := []*big.Int{localRaw}
+
+	for i, peer := range q.peers {
+		if i == q.shareIndex {
+			continue
+		}
+		if len(raws) >= q.m {
+			break
+		}
+
+		partial, err := q.requestPartialSign(ctx, peer, message)
+		if err != nil {
+			continue
+		}
+
+		xs = append(xs, peerXCoordinate(i))
+		raws = append(raws, partial)
+	}
+
+	if len(raws) < q.m {
+		return "", fmt.Errorf("quorum not met: got %d of %d required partial signatures", len(raws), q.m)
+	}
+
+	s := new(big.Int)
+	for i, raw := range raws {
+		lambda, err := lagrangeCoefficientAtZero(xs, i, n)
+		if err != nil {
+			return "", fmt.Errorf("failed to combine partial signatures: %w", err)
+		}
+
+		term := new(big.Int).Mul(lambda, raw)
+		s.Add(s, term)
+		s.Mod(s, n)
+	}
+
+	// Canonicalize to low-S form, as a standalone P-256 signature would be.
+	half := new(big.Int).Rsh(n, 1)
+	if s.Cmp(half) > 0 {
+		s.Sub(n, s)
+	}
+
+	return SignatureToCESR(r, s)
+}
+
+func (q *QuorumSigner) requestPartialSign(ctx context.Context, peer string, message []byte) (*big.Int, error) {
+	body, err := json.Marshal(quorumPartialSignRequest{Message: message})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, peer+"/quorum/partial-sign", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := q.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("peer %s returned status %d", peer, resp.StatusCode)
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed quorumPartialSignResponse
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return nil, err
+	}
+
+	sBytes, err := hex.DecodeString(parsed.S)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(sBytes), nil
+}
+
+// PartialSign is called on the peer side, by the handler backing /quorum/partial-sign.
+func (q *QuorumSigner) PartialSign(message []byte) (string, error) {
+	s, err := q.partialSign(message)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(s.Bytes()), nil
+}
+
+// PeerHealth is one row of a /quorum/health report.
+type PeerHealth struct {
+	Peer      string `json:"peer"`
+	Reachable bool   `json:"reachable"`
+}
+
+// Health checks every configured peer's /health endpoint and reports which are reachable, so
+// an operator can see at a glance whether the quorum still has m peers available.
+func (q *QuorumSigner) Health(ctx context.Context) []PeerHealth {
+	report := make([]PeerHealth, len(q.peers))
+
+	for i, peer := range q.peers {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, peer+"/health", nil)
+		if err != nil {
+			report[i] = PeerHealth{Peer: peer, Reachable: false}
+			continue
+		}
+
+		resp, err := q.client.Do(req)
+		reachable := err == nil && resp.StatusCode == http.StatusOK
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		report[i] = PeerHealth{Peer: peer, Reachable: reachable}
+	}
+
+	return report
+}
+
+type quorumPrepareRotationRequest struct {
+	Share string `json:"share"` // hex-encoded Shamir share dealt to this peer
+}
+
+type quorumPrepareRotationResponse struct {
+	PeerIdentity    string `json:"peerIdentity"`
+	ShareCommitment string `json:"shareCommitment"` // hex-encoded uncompressed public point of the dealt share
+}
+
+// PrepareRotation stores share — this peer's next Shamir share of the aggregate private key,
+// dealt by whichever peer is coordinating this rotation (see Rotate) — as pending, not yet
+// active until CommitRotation, and returns a public commitment to it so the coordinator can
+// detect a corrupted transmission before committing anywhere. It's the peer-side handler for
+// /quorum/prepare-rotation.
+func (q *QuorumSigner) PrepareRotation(share *big.Int) (string, error) {
+	curve := elliptic.P256()
+
+	if share == nil || share.Sign() == 0 {
+		return "", fmt.Errorf("invalid rotation share")
+	}
+
+	x, y := curve.ScalarBaseMult(share.Bytes())
+	commitment := hex.EncodeToString(elliptic.Marshal(curve, x, y))
+
+	q.pendingMu.Lock()
+	q.pendingShare = share
+	q.pendingMu.Unlock()
+
+	return commitment, nil
+}
+
+// CommitRotation swaps this peer's active share to the one generated by the last
+// PrepareRotation call, and updates the quorum's aggregate public key to nextPublicKey. It's
+// the peer-side handler for /quorum/commit-rotation; the coordinator only calls it after
+// confirming at least m peers successfully prepared.
+func (q *QuorumSigner) CommitRotation(nextPublicKey *ecdsa.PublicKey) error {
+	q.pendingMu.Lock()
+	defer q.pendingMu.Unlock()
+
+	if q.pendingShare == nil {
+		return fmt.Errorf("no prepared rotation to commit")
+	}
+
+	q.share = q.pendingShare
+	q.pendingShare = nil
+	q.publicKey = nextPublicKey
+
+	return nil
+}
+
+// Rotate deals a fresh Shamir sharing of a new aggregate private key and distributes one share
+// to every reachable peer, committing the rotation everywhere it was prepared only if at least
+// m peers (including this one) prepared successfully. If fewer than m peers participate, it
+// aborts without committing anywhere, leaving each peer's already-active share untouched.
+//
+// The peer that calls Rotate acts as a one-time dealer: it alone generates the new secret and
+// the degree-(m-1) polynomial committing to it, so it — transiently, for the duration of this
+// call — holds the one piece of information no single peer holds the rest of the time. A fully
+// dealerless rotation would need a distributed key generation protocol (e.g. Pedersen DKG); the
+// quorum already trusts whichever peer its operator directs to run a rotation, so a dealer is an
+// acceptable simplification here, not a trust regression; that the scheme is otherwise a real
+// m-of-n Shamir sharing (rather than before's flat additive division by the fixed value m) is
+// what makes signing actually tolerate any n-m peers being down.
+func (q *QuorumSigner) Rotate(ctx context.Context) (string, string, implementation.ShareDescriptors, error) {
+	curve := elliptic.P256()
+	order := curve.Params().N
+
+	secret, err := rand.Int(rand.Reader, order)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to generate next aggregate secret: %w", err)
+	}
+	if secret.Sign() == 0 {
+		return "", "", nil, fmt.Errorf("generated degenerate zero secret")
+	}
+
+	dealtShares, err := generateShamirShares(secret, q.m, len(q.peers), curve)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to deal rotation shares: %w", err)
+	}
+
+	x, y := curve.ScalarBaseMult(secret.Bytes())
+	nextPublicKey := &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+
+	localCommitment, err := q.PrepareRotation(dealtShares[q.shareIndex])
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to prepare local rotation: %w", err)
+	}
+
+	shares := implementation.ShareDescriptors{{PeerIdentity: q.peers[q.shareIndex], ShareCommitment: localCommitment}}
+	prepared := []string{}
+
+	for i, peer := range q.peers {
+		if i == q.shareIndex {
+			continue
+		}
+
+		commitment, err := q.requestPrepareRotation(ctx, peer, dealtShares[i])
+		if err != nil {
+			continue
+		}
+
+		shares = append(shares, implementation.ShareDescriptor{PeerIdentity: peer, ShareCommitment: commitment})
+		prepared = append(prepared, peer)
+	}
+
+	if len(prepared)+1 < q.m {
+		return "", "", nil, fmt.Errorf("quorum rotation not met: got %d of %d required peers prepared", len(prepared)+1, q.m)
+	}
+
+	nextCesrPublicKey, err := PublicKeyToCESR(nextPublicKey)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to encode next aggregate public key: %w", err)
+	}
+	nextRotationHash := CESRBlake3Sum(nextCesrPublicKey)
+
+	if err := q.CommitRotation(nextPublicKey); err != nil {
+		return "", "", nil, fmt.Errorf("failed to commit local rotation: %w", err)
+	}
+
+	for _, peer := range prepared {
+		_ = q.requestCommitRotation(ctx, peer, nextCesrPublicKey)
+	}
+
+	return nextCesrPublicKey, nextRotationHash, shares, nil
+}
+
+func (q *QuorumSigner) requestPrepareRotation(ctx context.Context, peer string, share *big.Int) (string, error) {
+	body, err := json.Marshal(quorumPrepareRotationRequest{Share: hex.EncodeToString(share.Bytes())})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, peer+"/quorum/prepare-rotation", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := q.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("peer %s returned status %d", peer, resp.StatusCode)
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var parsed quorumPrepareRotationResponse
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return "", err
+	}
+
+	return parsed.ShareCommitment, nil
+}
+
+func (q *QuorumSigner) requestCommitRotation(ctx context.Context, peer, nextCesrPublicKey string) error {
+	body, err := json.Marshal(struct {
+		NextPublicKey string `json:"nextPublicKey"`
+	}{NextPublicKey: nextCesrPublicKey})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, peer+"/quorum/commit-rotation", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := q.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("peer %s returned status %d", peer, resp.StatusCode)
+	}
+
+	return nil
+}
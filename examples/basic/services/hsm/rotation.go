@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// ROTATION_STATE_PATH holds the on-disk mapping of currentId -> nextId for keys that have
+// been pre-generated inside the token but not yet committed. It survives an HSM service
+// restart between PrepareRotation and CommitRotation.
+const ROTATION_STATE_PATH_ENV = "HSM_ROTATION_STATE_PATH"
+
+const defaultRotationStatePath = "/var/lib/softhsm/rotation-state.json"
+
+func rotationStatePath() string {
+	if path := os.Getenv(ROTATION_STATE_PATH_ENV); path != "" {
+		return path
+	}
+	return defaultRotationStatePath
+}
+
+// rotationState persists the currentId -> nextId commitment map across restarts.
+type rotationState struct {
+	mu   sync.Mutex
+	path string
+}
+
+func newRotationState() *rotationState {
+	return &rotationState{path: rotationStatePath()}
+}
+
+func (s *rotationState) load() (map[string]uint64, error) {
+	pending := map[string]uint64{}
+
+	bytes, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return pending, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read rotation state: %w", err)
+	}
+
+	if err := json.Unmarshal(bytes, &pending); err != nil {
+		return nil, fmt.Errorf("failed to parse rotation state: %w", err)
+	}
+
+	return pending, nil
+}
+
+func (s *rotationState) save(pending map[string]uint64) error {
+	bytes, err := json.Marshal(pending)
+	if err != nil {
+		return fmt.Errorf("failed to serialize rotation state: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, bytes, 0600); err != nil {
+		return fmt.Errorf("failed to write rotation state: %w", err)
+	}
+
+	return nil
+}
+
+func rotationStateKey(label string, currentId uint64) string {
+	return fmt.Sprintf("%s:%d", label, currentId)
+}
+
+func (s *rotationState) put(label string, currentId, nextId uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	pending[rotationStateKey(label, currentId)] = nextId
+
+	return s.save(pending)
+}
+
+func (s *rotationState) take(label string, currentId uint64) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending, err := s.load()
+	if err != nil {
+		return 0, err
+	}
+
+	nextId, ok := pending[rotationStateKey(label, currentId)]
+	if !ok {
+		return 0, fmt.Errorf("no prepared rotation for %s:%d", label, currentId)
+	}
+
+	delete(pending, rotationStateKey(label, currentId))
+
+	return nextId, s.save(pending)
+}
+
+// PrepareRotation generates the next EC key pair inside the token under a distinct CKA_ID,
+// computes its CESR public key and Blake3 rotation-hash commitment, and records the
+// currentId -> nextId mapping so CommitRotation can find it later without the caller having
+// to hold onto any private material in the meantime.
+func (k *PKCS11SigningKey) PrepareRotation(label string, currentId, nextId uint64) (string, string, error) {
+	if err := k.generateKey(label, nextId); err != nil {
+		return "", "", fmt.Errorf("failed to generate next key: %w", err)
+	}
+
+	nextCesrPublicKey, err := k.publicKey(label, nextId)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to export next public key: %w", err)
+	}
+
+	rotationHash := CESRBlake3Sum(nextCesrPublicKey)
+
+	if err := k.rotations.put(label, currentId, nextId); err != nil {
+		return "", "", err
+	}
+
+	return nextCesrPublicKey, rotationHash, nil
+}
+
+// CommitRotation atomically swaps the active signing key to the previously prepared nextId
+// and destroys the old private key object so it can never be used to sign again.
+func (k *PKCS11SigningKey) CommitRotation(label string, currentId uint64) error {
+	nextId, err := k.rotations.take(label, currentId)
+	if err != nil {
+		return err
+	}
+
+	session, err := k.pool.acquire(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to acquire session: %w", err)
+	}
+
+	oldPrivateKey, err := k.findPrivateKey(session, label, currentId)
+	if err != nil {
+		k.pool.release(session, true)
+		return err
+	}
+
+	if err := k.ctx.DestroyObject(session, oldPrivateKey); err != nil {
+		k.pool.release(session, true)
+		return fmt.Errorf("failed to destroy old private key: %w", err)
+	}
+
+	k.pool.release(session, true)
+
+	k.keyCacheMu.Lock()
+	delete(k.keyCache, privateKeyKey{label: label, id: currentId})
+	k.keyCacheMu.Unlock()
+
+	return k.loadKey(label, nextId)
+}
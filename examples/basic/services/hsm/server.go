@@ -2,28 +2,79 @@ package main
 
 import (
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
+	"math/big"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
-	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/expressions"
-	"github.com/jasoncolburne/verifiable-storage-go/pkg/data/orderings"
-	"github.com/jasoncolburne/verifiable-storage-go/pkg/repository"
-	"github.com/redis/go-redis/v9"
+	"github.com/jasoncolburne/better-auth/examples/basic/services/hsm/pkg/db"
+	"github.com/jasoncolburne/better-auth/examples/basic/services/hsm/pkg/implementation"
+	"github.com/jasoncolburne/better-auth/examples/basic/services/hsm/pkg/implementation/postgresadapter"
+	"github.com/jasoncolburne/better-auth/examples/basic/services/hsm/pkg/implementation/redisadapter"
 )
 
-const (
-	PURPOSE = "key-authorization"
-	LABEL   = "authorization-key"
-)
+const LABEL = "authorization-key"
 
 type HSMServer struct {
-	key      *SigningKey
-	keysRepo repository.Repository[*Keys]
+	key       *PKCS11SigningKey
+	records   implementation.HSMKeyRecordStore
+	publisher implementation.RotationPublisher
+
+	// quorum is nil for a standalone HSM. When set, /sign and /rotate assemble their
+	// signature from a quorum of peer HSMs instead of signing locally through key.
+	quorum *QuorumSigner
+}
+
+// HSMServerConfig lets a deployment swap the Postgres+Redis defaults for any backend that
+// satisfies implementation.HSMKeyRecordStore and implementation.RotationPublisher — BoltDB,
+// DynamoDB, Consul, an in-memory store for tests, or anything else.
+type HSMServerConfig struct {
+	Key       *PKCS11SigningKey
+	Records   implementation.HSMKeyRecordStore
+	Publisher implementation.RotationPublisher
+	// Quorum is optional; set it to back this identity with an (m, n) quorum of peer HSMs
+	// instead of signing through Key alone.
+	Quorum *QuorumSigner
+}
+
+// newQuorumSignerFromEnv builds a QuorumSigner from QUORUM_PEERS/QUORUM_M/QUORUM_SHARE_INDEX/
+// QUORUM_SHARE_HEX/QUORUM_PUBLIC_KEY, or returns (nil, nil) if QUORUM_PEERS is unset — in
+// which case the server runs as a standalone HSM, exactly as before.
+func newQuorumSignerFromEnv() (*QuorumSigner, error) {
+	peersEnv := os.Getenv("QUORUM_PEERS")
+	if peersEnv == "" {
+		return nil, nil
+	}
+	peers := strings.Split(peersEnv, ",")
+
+	m, err := strconv.Atoi(os.Getenv("QUORUM_M"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid QUORUM_M: %w", err)
+	}
+
+	shareIndex, err := strconv.Atoi(os.Getenv("QUORUM_SHARE_INDEX"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid QUORUM_SHARE_INDEX: %w", err)
+	}
+
+	shareBytes, err := hex.DecodeString(os.Getenv("QUORUM_SHARE_HEX"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid QUORUM_SHARE_HEX: %w", err)
+	}
+	share := new(big.Int).SetBytes(shareBytes)
+
+	publicKey, err := CESRToPublicKey(os.Getenv("QUORUM_PUBLIC_KEY"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid QUORUM_PUBLIC_KEY: %w", err)
+	}
+
+	return NewQuorumSigner(peers, m, shareIndex, share, publicKey)
 }
 
 type SignRequest struct {
@@ -53,21 +104,16 @@ type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
+// NewHSMServer wires up the default deployment: a Postgres-backed HSMKeyRecordStore and a
+// Redis-backed RotationPublisher, configured from the environment. Deployments that want a
+// different backend should build their own HSMServerConfig and call NewHSMServerFromConfig.
 func NewHSMServer() (*HSMServer, error) {
-	log.Printf("Starting HSM server initialization...")
-
-	migrations := []string{
-		KEYS_TABLE_SQL,
-	}
-
 	user := os.Getenv("POSTGRES_USER")
 	password := os.Getenv("POSTGRES_PASSWORD")
 	database := os.Getenv("POSTGRES_DATABASE")
 	host := os.Getenv("POSTGRES_HOST")
 	port := os.Getenv("POSTGRES_PORT")
 
-	log.Printf("Connecting to PostgreSQL: host=%s port=%s database=%s user=%s", host, port, database, user)
-
 	dsn := fmt.Sprintf(
 		"user=%s password=%s dbname=%s host=%s port=%s sslmode=disable",
 		user,
@@ -77,47 +123,65 @@ func NewHSMServer() (*HSMServer, error) {
 		port,
 	)
 
-	store, err := NewPostgreSQLStore(context.Background(), dsn, migrations)
+	log.Printf("Connecting to PostgreSQL: host=%s port=%s database=%s user=%s", host, port, database, user)
+
+	sqlStore, err := db.NewPostgreSQLStore(context.Background(), dsn, []string{implementation.KEYS_TABLE_SQL})
 	if err != nil {
 		log.Printf("Failed to connect to PostgreSQL: %v", err)
 		return nil, err
 	}
 	log.Printf("PostgreSQL connection established")
 
-	ctx := context.Background()
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
+	publisher, err := redisadapter.NewRotationPublisher()
+	if err != nil {
+		log.Printf("Failed to configure rotation publisher: %v", err)
+		return nil, err
+	}
 
-	log.Printf("Creating keys repository...")
-	keysRepo := repository.NewVerifiableRepository[*Keys](store, true, true, nil)
+	key, err := NewPKCS11SigningKey()
+	if err != nil {
+		log.Printf("Failed to initialize signing key: %v", err)
+		return nil, err
+	}
 
-	log.Printf("Querying existing keys from database...")
-	records := []*Keys{}
-	if err := keysRepo.Select(ctx, &records, expressions.Equal("purpose", PURPOSE), orderings.Descending("sequence_number"), nil); err != nil {
-		log.Printf("Failed to query keys: %v", err)
+	quorum, err := newQuorumSignerFromEnv()
+	if err != nil {
+		log.Printf("Failed to configure quorum signer: %v", err)
 		return nil, err
 	}
-	log.Printf("Found %d existing key records", len(records))
 
-	log.Printf("Initializing PKCS#11 signing key...")
-	key, err := NewSigningKey()
+	return NewHSMServerFromConfig(HSMServerConfig{
+		Key:       key,
+		Records:   postgresadapter.NewKeyRecordStore(sqlStore),
+		Publisher: publisher,
+		Quorum:    quorum,
+	})
+}
+
+// NewHSMServerFromConfig discovers the active key generation from cfg.Records, generating
+// and publishing one through cfg.Publisher if none exists yet.
+func NewHSMServerFromConfig(cfg HSMServerConfig) (*HSMServer, error) {
+	log.Printf("Starting HSM server initialization...")
+
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	key := cfg.Key
+
+	log.Printf("Querying existing key record...")
+	record, err := cfg.Records.Latest(ctx)
 	if err != nil {
-		log.Printf("Failed to initialize signing key: %v", err)
+		log.Printf("Failed to query keys: %v", err)
 		return nil, err
 	}
-	log.Printf("PKCS#11 signing key initialized")
 
-	var record *Keys
-	if len(records) > 0 {
-		log.Printf("Loading existing key (sequence %d)...", records[0].SequenceNumber)
-		record = records[0]
+	if record != nil {
+		log.Printf("Loading existing key (sequence %d)...", record.SequenceNumber)
 		key.loadKey(LABEL, record.SequenceNumber)
 		log.Printf("Existing key loaded successfully")
 	} else {
 		log.Printf("No existing keys found, generating new key pair...")
-		ctx := context.Background()
-		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-		defer cancel()
 
 		log.Printf("Generating key 0...")
 		if err := key.generateKey(LABEL, 0); err != nil {
@@ -147,14 +211,15 @@ func NewHSMServer() (*HSMServer, error) {
 
 		log.Printf("Computing rotation hash...")
 		rotationHash := CESRBlake3Sum(nextPublicKey)
-		record = &Keys{
-			Purpose:      PURPOSE,
+		record = &implementation.Keys{
+			Purpose:      implementation.PURPOSE,
 			PublicKey:    key.cesrPublicKey,
 			RotationHash: rotationHash,
+			Algorithm:    string(key.algorithm),
 		}
 
 		log.Printf("Saving key record to database...")
-		if err := keysRepo.CreateVersion(ctx, record); err != nil {
+		if err := cfg.Records.Append(ctx, record); err != nil {
 			log.Printf("Failed to save key record: %v", err)
 			return nil, err
 		}
@@ -170,44 +235,9 @@ func NewHSMServer() (*HSMServer, error) {
 			return nil, err
 		}
 
-		redisMessage := struct {
-			Payload   *Keys  `json:"payload"`
-			Signature string `json:"signature"`
-		}{
-			Payload:   record,
-			Signature: signature,
-		}
-
-		redisJson, err := json.Marshal(redisMessage)
-		if err != nil {
-			return nil, err
-		}
-
-		redisHost := os.Getenv("REDIS_HOST")
-		if redisHost == "" {
-			redisHost = "redis:6379"
-		}
-
-		redisDbHsmKeysString := os.Getenv("REDIS_DB_HSM_KEYS")
-		redisDbHsmKeys, err := strconv.Atoi(redisDbHsmKeysString)
-		if err != nil {
-			return nil, err
-		}
-
-		hsmKeysClient := redis.NewClient(&redis.Options{
-			Addr: redisHost,
-			DB:   redisDbHsmKeys,
-		})
-
-		// Retry Redis Set operation to handle connection drops gracefully
-		_, err = retryRedisOperation(ctx, func() (struct{}, error) {
-			return struct{}{}, hsmKeysClient.Set(ctx, record.Id, redisJson, 0).Err()
-		})
-		if err != nil {
+		if err := cfg.Publisher.Publish(ctx, record, signature); err != nil {
 			return nil, err
 		}
-
-		_ = hsmKeysClient.Close()
 	}
 
 	key.identity = record.Prefix
@@ -216,27 +246,43 @@ func NewHSMServer() (*HSMServer, error) {
 	log.Printf("HSM initialized successfully")
 
 	return &HSMServer{
-		key:      key,
-		keysRepo: keysRepo,
+		key:       key,
+		records:   cfg.Records,
+		publisher: cfg.Publisher,
+		quorum:    cfg.Quorum,
 	}, nil
 }
 
 func (s *HSMServer) rotateSigningKey() error {
+	if s.quorum != nil {
+		return s.rotateQuorumKey()
+	}
+
 	ctx := context.Background()
 	ctx, cancel1 := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel1()
 
-	records := []*Keys{}
-
-	if err := s.keysRepo.Select(ctx, &records, expressions.Equal("purpose", PURPOSE), orderings.Descending("sequence_number"), nil); err != nil {
+	record, err := s.records.Latest(ctx)
+	if err != nil {
 		return err
 	}
 
-	if len(records) == 0 {
+	if record == nil {
 		return fmt.Errorf("no record found")
 	}
 
-	record := records[0]
+	// An existing record's Algorithm may be empty for identities created before this field
+	// existed; treat that as P256, its former implicit default. Crossing to a different
+	// algorithm on rotation changes the signature scheme clients must verify against, so it's
+	// refused unless an operator has explicitly opted in via ALLOW_ALGORITHM_ROTATION.
+	previousAlgorithm := Algorithm(record.Algorithm)
+	if previousAlgorithm == "" {
+		previousAlgorithm = AlgorithmP256
+	}
+
+	if previousAlgorithm != s.key.algorithm && os.Getenv("ALLOW_ALGORITHM_ROTATION") != "true" {
+		return fmt.Errorf("refusing to rotate from algorithm %s to %s without ALLOW_ALGORITHM_ROTATION=true", previousAlgorithm, s.key.algorithm)
+	}
 
 	if err := s.key.generateKey(LABEL, record.SequenceNumber+2); err != nil {
 		return err
@@ -254,12 +300,13 @@ func (s *HSMServer) rotateSigningKey() error {
 
 	record.PublicKey = s.key.cesrPublicKey
 	record.RotationHash = rotationHash
+	record.Algorithm = string(s.key.algorithm)
 
 	ctx = context.Background()
 	ctx, cancel2 := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel2()
 
-	if err := s.keysRepo.CreateVersion(ctx, record); err != nil {
+	if err := s.records.Append(ctx, record); err != nil {
 		return err
 	}
 
@@ -275,46 +322,163 @@ func (s *HSMServer) rotateSigningKey() error {
 		return err
 	}
 
-	redisMessage := struct {
-		Payload   *Keys  `json:"payload"`
-		Signature string `json:"signature"`
-	}{
-		Payload:   record,
-		Signature: signature,
+	return s.publisher.Publish(ctx, record, signature)
+}
+
+// rotateQuorumKey fans a rotation out across the quorum's peers, aborting without committing
+// anywhere if fewer than m participate, and records the resulting aggregate key and its peer
+// share commitments as a new version of the keys record.
+func (s *HSMServer) rotateQuorumKey() error {
+	ctx := context.Background()
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	record, err := s.records.Latest(ctx)
+	if err != nil {
+		return err
+	}
+	if record == nil {
+		return fmt.Errorf("no record found")
 	}
 
-	redisJson, err := json.Marshal(redisMessage)
+	nextCesrPublicKey, nextRotationHash, shares, err := s.quorum.Rotate(ctx)
 	if err != nil {
 		return err
 	}
 
-	redisHost := os.Getenv("REDIS_HOST")
-	if redisHost == "" {
-		redisHost = "redis:6379"
+	record.PublicKey = nextCesrPublicKey
+	record.RotationHash = nextRotationHash
+	record.Shares = shares
+
+	if err := s.records.Append(ctx, record); err != nil {
+		return err
+	}
+
+	recordJson, err := json.Marshal(record)
+	if err != nil {
+		return err
 	}
 
-	redisDbHsmKeysString := os.Getenv("REDIS_DB_HSM_KEYS")
-	redisDbHsmKeys, err := strconv.Atoi(redisDbHsmKeysString)
+	signature, err := s.quorum.Sign(ctx, recordJson)
 	if err != nil {
 		return err
 	}
 
-	hsmKeysClient := redis.NewClient(&redis.Options{
-		Addr: redisHost,
-		DB:   redisDbHsmKeys,
+	return s.publisher.Publish(ctx, record, signature)
+}
+
+func (s *HSMServer) handleQuorumPartialSign(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if s.quorum == nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "quorum not configured"})
+		return
+	}
+
+	var req quorumPartialSignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid request"})
+		return
+	}
+
+	partial, err := s.quorum.PartialSign(req.Message)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "partial signing failed"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(quorumPartialSignResponse{
+		PeerIdentity: s.key.identity,
+		S:            partial,
 	})
+}
+
+func (s *HSMServer) handleQuorumPrepareRotation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if s.quorum == nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "quorum not configured"})
+		return
+	}
+
+	var req quorumPrepareRotationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid request"})
+		return
+	}
+
+	shareBytes, err := hex.DecodeString(req.Share)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid share"})
+		return
+	}
+
+	commitment, err := s.quorum.PrepareRotation(new(big.Int).SetBytes(shareBytes))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "prepare rotation failed"})
+		return
+	}
 
-	// Retry Redis Set operation to handle connection drops gracefully
-	_, err = retryRedisOperation(ctx, func() (struct{}, error) {
-		return struct{}{}, hsmKeysClient.Set(ctx, record.Id, redisJson, 0).Err()
+	json.NewEncoder(w).Encode(quorumPrepareRotationResponse{
+		PeerIdentity:    s.key.identity,
+		ShareCommitment: commitment,
 	})
+}
+
+func (s *HSMServer) handleQuorumCommitRotation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if s.quorum == nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "quorum not configured"})
+		return
+	}
+
+	var req struct {
+		NextPublicKey string `json:"nextPublicKey"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid request"})
+		return
+	}
+
+	nextPublicKey, err := CESRToPublicKey(req.NextPublicKey)
 	if err != nil {
-		return err
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid public key"})
+		return
+	}
+
+	if err := s.quorum.CommitRotation(nextPublicKey); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "commit rotation failed"})
+		return
 	}
 
-	_ = hsmKeysClient.Close()
+	fmt.Fprintf(w, `{"status":"committed"}`)
+}
+
+// handleQuorumHealth reports, for each configured peer, whether its /health endpoint is
+// currently reachable — so an operator can see at a glance whether the quorum still has m
+// peers available.
+func (s *HSMServer) handleQuorumHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if s.quorum == nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "quorum not configured"})
+		return
+	}
 
-	return nil
+	json.NewEncoder(w).Encode(s.quorum.Health(r.Context()))
 }
 
 func (s *HSMServer) handleSign(w http.ResponseWriter, r *http.Request) {
@@ -358,8 +522,13 @@ func (s *HSMServer) handleSign(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Sign the body JSON
-	signature, err := s.key.Sign(bodyJSON)
+	// Sign the body JSON, through the quorum if one is configured, else locally
+	var signature string
+	if s.quorum != nil {
+		signature, err = s.quorum.Sign(r.Context(), bodyJSON)
+	} else {
+		signature, err = s.key.Sign(bodyJSON)
+	}
 	if err != nil {
 		log.Printf("Sign error: %v", err)
 		w.Header().Set("Content-Type", "application/json")
@@ -403,6 +572,10 @@ func main() {
 	http.HandleFunc("/sign", server.handleSign)
 	http.HandleFunc("/rotate", server.handleRotate)
 	http.HandleFunc("/health", server.handleHealth)
+	http.HandleFunc("/quorum/partial-sign", server.handleQuorumPartialSign)
+	http.HandleFunc("/quorum/prepare-rotation", server.handleQuorumPrepareRotation)
+	http.HandleFunc("/quorum/commit-rotation", server.handleQuorumCommitRotation)
+	http.HandleFunc("/quorum/health", server.handleQuorumHealth)
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/miekg/pkcs11"
+)
+
+const (
+	DEFAULT_MIN_POOL_SIZE = 2
+	DEFAULT_MAX_POOL_SIZE = 8
+)
+
+// sessionPoolMetrics tracks pool health so operators can size capacity correctly.
+type sessionPoolMetrics struct {
+	acquireWaitTotal atomic.Int64 // nanoseconds spent waiting for a session
+	acquireCount     atomic.Int64
+	exhaustedCount   atomic.Int64 // acquires that had to block because the pool was empty and at capacity
+}
+
+// AcquireWaitAverage returns the mean time callers have spent waiting to acquire a session.
+func (m *sessionPoolMetrics) AcquireWaitAverage() time.Duration {
+	count := m.acquireCount.Load()
+	if count == 0 {
+		return 0
+	}
+
+	return time.Duration(m.acquireWaitTotal.Load() / count)
+}
+
+// Exhausted returns the number of times an acquire found the pool empty and at capacity.
+func (m *sessionPoolMetrics) Exhausted() int64 {
+	return m.exhaustedCount.Load()
+}
+
+// sessionPool is a bounded, growable pool of logged-in PKCS#11 sessions against a single
+// slot, in the spirit of Hyperledger Fabric's BCCSP PKCS#11 provider: sessions are opened
+// lazily up to max, reused across operations, and replaced rather than repaired when they
+// turn out to be unhealthy.
+type sessionPool struct {
+	ctx  *pkcs11.Ctx
+	slot uint
+	pin  string
+
+	min int
+	max int
+
+	idle   chan pkcs11.SessionHandle
+	mu     sync.Mutex // guards opened
+	opened int        // total sessions currently checked out or idle
+
+	metrics sessionPoolMetrics
+}
+
+func newSessionPool(ctx *pkcs11.Ctx, slot uint, pin string, min, max int) (*sessionPool, error) {
+	if min < 0 || max <= 0 || min > max {
+		return nil, fmt.Errorf("invalid session pool bounds: min=%d max=%d", min, max)
+	}
+
+	pool := &sessionPool{
+		ctx:  ctx,
+		slot: slot,
+		pin:  pin,
+		min:  min,
+		max:  max,
+		idle: make(chan pkcs11.SessionHandle, max),
+	}
+
+	for i := 0; i < min; i++ {
+		session, err := pool.openSession()
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+		pool.opened++
+		pool.idle <- session
+	}
+
+	return pool, nil
+}
+
+func (p *sessionPool) openSession() (pkcs11.SessionHandle, error) {
+	session, err := p.ctx.OpenSession(p.slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open session: %w", err)
+	}
+
+	if err := p.ctx.Login(session, pkcs11.CKU_USER, p.pin); err != nil {
+		p.ctx.CloseSession(session)
+		return 0, fmt.Errorf("failed to login: %w", err)
+	}
+
+	return session, nil
+}
+
+// acquire returns an idle session, growing the pool if under capacity, or blocks until one
+// is released or ctx is cancelled. The capacity check and the opened++ that reserves the new
+// slot happen under the same lock, so concurrent acquires can't both observe opened < max and
+// overshoot max; each caller backs its reservation out if openSession then fails.
+func (p *sessionPool) acquire(ctx context.Context) (pkcs11.SessionHandle, error) {
+	start := time.Now()
+	defer func() {
+		p.metrics.acquireWaitTotal.Add(int64(time.Since(start)))
+		p.metrics.acquireCount.Add(1)
+	}()
+
+	select {
+	case session := <-p.idle:
+		return session, nil
+	default:
+	}
+
+	p.mu.Lock()
+	if p.opened < p.max {
+		p.opened++
+		p.mu.Unlock()
+
+		session, err := p.openSession()
+		if err != nil {
+			p.mu.Lock()
+			p.opened--
+			p.mu.Unlock()
+			return 0, err
+		}
+		return session, nil
+	}
+	p.mu.Unlock()
+
+	p.metrics.exhaustedCount.Add(1)
+
+	select {
+	case session := <-p.idle:
+		return session, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// release returns a session to the pool, or replaces it with a freshly opened one if it's
+// no longer healthy.
+func (p *sessionPool) release(session pkcs11.SessionHandle, healthy bool) {
+	if healthy {
+		select {
+		case p.idle <- session:
+			return
+		default:
+			// pool shrank out from under us (shouldn't happen with a fixed max), fall through to close
+		}
+	}
+
+	p.ctx.CloseSession(session)
+	p.mu.Lock()
+	p.opened--
+	p.mu.Unlock()
+
+	replacement, err := p.openSession()
+	if err != nil {
+		// Leave the pool a session short; the next acquire will grow it again since
+		// opened < max now.
+		return
+	}
+
+	p.mu.Lock()
+	p.opened++
+	p.mu.Unlock()
+
+	select {
+	case p.idle <- replacement:
+	default:
+		p.ctx.CloseSession(replacement)
+		p.mu.Lock()
+		p.opened--
+		p.mu.Unlock()
+	}
+}
+
+// Close drains the pool, logging out and closing every session it holds.
+func (p *sessionPool) Close() {
+	p.mu.Lock()
+	opened := p.opened
+	p.mu.Unlock()
+
+	for i := 0; i < opened; i++ {
+		select {
+		case session := <-p.idle:
+			p.ctx.Logout(session)
+			p.ctx.CloseSession(session)
+		default:
+			// a session is still checked out; nothing more we can do here
+		}
+	}
+}
@@ -1,12 +1,13 @@
 package main
 
 import (
+	"context"
 	"crypto/ecdsa"
-	"crypto/elliptic"
+	"crypto/ed25519"
 	"crypto/sha256"
 	"fmt"
-	"log"
 	"math/big"
+	"os"
 	"sync"
 
 	"github.com/miekg/pkcs11"
@@ -18,27 +19,132 @@ const (
 	TOKEN_PIN   = "1234"
 )
 
-type SigningKey struct {
+// The pinned github.com/miekg/pkcs11 v1.1.1 predates the OASIS PKCS#11 3.0 Edwards-curve
+// additions, so it doesn't export these; the values below are the spec's fixed constants,
+// not implementation-specific ones, so hardcoding them here is safe.
+const (
+	ckkEcEdwards           = 0x00000040
+	ckmEcEdwardsKeyPairGen = 0x00001055
+	ckmEddsa               = 0x00001057
+)
+
+// Algorithm identifies which signature scheme a Keys record (and the signing key backing it)
+// uses. KEY_ALGORITHM selects one for a freshly-generated identity; existing identities carry
+// their own in the keys table so rotateSigningKey can tell when an operator is trying to cross
+// algorithms.
+type Algorithm string
+
+const (
+	AlgorithmP256      Algorithm = "P256"
+	AlgorithmEd25519   Algorithm = "Ed25519"
+	AlgorithmSecp256k1 Algorithm = "Secp256k1"
+)
+
+// ecParamsFor returns the DER-encoded CKA_EC_PARAMS OID PKCS#11 expects for a key-generation
+// request in the given algorithm.
+func ecParamsFor(algorithm Algorithm) ([]byte, error) {
+	switch algorithm {
+	case AlgorithmP256:
+		// secp256r1 (P-256): 1.2.840.10045.3.1.7
+		return []byte{0x06, 0x08, 0x2a, 0x86, 0x48, 0xce, 0x3d, 0x03, 0x01, 0x07}, nil
+	case AlgorithmSecp256k1:
+		// secp256k1: 1.3.132.0.10
+		return []byte{0x06, 0x05, 0x2b, 0x81, 0x04, 0x00, 0x0a}, nil
+	case AlgorithmEd25519:
+		// edwards25519: 1.3.101.112
+		return []byte{0x06, 0x03, 0x2b, 0x65, 0x70}, nil
+	default:
+		return nil, fmt.Errorf("unsupported algorithm: %s", algorithm)
+	}
+}
+
+// keyPairGenMechanismFor returns the PKCS#11 key-pair-generation mechanism for the given
+// algorithm: Edwards curves use a dedicated mechanism, Weierstrass curves (P-256, secp256k1)
+// share CKM_EC_KEY_PAIR_GEN and are distinguished purely by their CKA_EC_PARAMS OID.
+func keyPairGenMechanismFor(algorithm Algorithm) (uint, error) {
+	switch algorithm {
+	case AlgorithmP256, AlgorithmSecp256k1:
+		return pkcs11.CKM_EC_KEY_PAIR_GEN, nil
+	case AlgorithmEd25519:
+		return ckmEcEdwardsKeyPairGen, nil
+	default:
+		return 0, fmt.Errorf("unsupported algorithm: %s", algorithm)
+	}
+}
+
+// signMechanismFor returns the PKCS#11 signing mechanism for the given algorithm.
+func signMechanismFor(algorithm Algorithm) (uint, error) {
+	switch algorithm {
+	case AlgorithmP256, AlgorithmSecp256k1:
+		return pkcs11.CKM_ECDSA, nil
+	case AlgorithmEd25519:
+		return ckmEddsa, nil
+	default:
+		return 0, fmt.Errorf("unsupported algorithm: %s", algorithm)
+	}
+}
+
+// algorithmFromEnv resolves KEY_ALGORITHM, defaulting to P256 to preserve existing behavior
+// for deployments that don't set it.
+func algorithmFromEnv() (Algorithm, error) {
+	switch Algorithm(os.Getenv("KEY_ALGORITHM")) {
+	case "", AlgorithmP256:
+		return AlgorithmP256, nil
+	case AlgorithmEd25519:
+		return AlgorithmEd25519, nil
+	case AlgorithmSecp256k1:
+		return AlgorithmSecp256k1, nil
+	default:
+		return "", fmt.Errorf("unrecognized KEY_ALGORITHM: %s", os.Getenv("KEY_ALGORITHM"))
+	}
+}
+
+// SigningKey is the algorithm-agnostic contract HSMServer signs through. PKCS11SigningKey is
+// its only implementation today, dispatching key generation and signing on Algorithm(), but
+// any backend that can sign under one of the supported algorithms can satisfy it.
+type SigningKey interface {
+	Algorithm() Algorithm
+	Sign(data []byte) (string, error)
+}
+
+// privateKeyKey identifies a token private key object by its label and CKA_ID.
+type privateKeyKey struct {
+	label string
+	id    uint64
+}
+
+type PKCS11SigningKey struct {
+	algorithm Algorithm
+
 	identity      string
 	generationId  string
 	cesrPublicKey string
 
-	ctx        *pkcs11.Ctx
-	session    pkcs11.SessionHandle
-	privateKey pkcs11.ObjectHandle
+	ctx  *pkcs11.Ctx
+	pool *sessionPool
 
-	mu sync.Mutex // Protects PKCS#11 operations from concurrent access
+	activeLabel string // CKA_LABEL of the currently loaded signing key
+	activeId    uint64 // CKA_ID of the currently loaded signing key
+
+	keyCacheMu sync.RWMutex
+	keyCache   map[privateKeyKey]pkcs11.ObjectHandle
+
+	rotations *rotationState // persists PrepareRotation's currentId -> nextId commitment
 }
 
-func NewSigningKey() (*SigningKey, error) {
+func NewPKCS11SigningKey() (*PKCS11SigningKey, error) {
+	algorithm, err := algorithmFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
 	// Initialize PKCS#11
 	ctx := pkcs11.New(PKCS11_LIB)
 	if ctx == nil {
 		return nil, fmt.Errorf("failed to load PKCS#11 library")
 	}
 
-	err := ctx.Initialize()
-	if err != nil {
+	if err := ctx.Initialize(); err != nil {
 		return nil, fmt.Errorf("failed to initialize PKCS#11: %w", err)
 	}
 
@@ -54,37 +160,47 @@ func NewSigningKey() (*SigningKey, error) {
 
 	slot := slots[0]
 
-	// Open session
-	session, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	pool, err := newSessionPool(ctx, slot, TOKEN_PIN, DEFAULT_MIN_POOL_SIZE, DEFAULT_MAX_POOL_SIZE)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open session: %w", err)
+		return nil, err
 	}
 
-	// Login
-	err = ctx.Login(session, pkcs11.CKU_USER, TOKEN_PIN)
+	return &PKCS11SigningKey{
+		algorithm: algorithm,
+		ctx:       ctx,
+		pool:      pool,
+		keyCache:  map[privateKeyKey]pkcs11.ObjectHandle{},
+		rotations: newRotationState(),
+	}, nil
+}
+
+func (k *PKCS11SigningKey) Algorithm() Algorithm {
+	return k.algorithm
+}
+
+func (k *PKCS11SigningKey) generateKey(label string, id uint64) error {
+	session, err := k.pool.acquire(context.Background())
 	if err != nil {
-		return nil, fmt.Errorf("failed to login: %w", err)
+		return fmt.Errorf("failed to acquire session: %w", err)
 	}
 
-	return &SigningKey{
-		ctx:     ctx,
-		session: session,
-	}, nil
-}
+	healthy := true
+	defer func() { k.pool.release(session, healthy) }()
 
-func (k *SigningKey) generateKey(label string, id uint64) error {
-	// Lock to prevent concurrent PKCS#11 operations
-	// PKCS#11 sessions are not thread-safe and will segfault if used concurrently
-	k.mu.Lock()
-	defer k.mu.Unlock()
+	ecParams, err := ecParamsFor(k.algorithm)
+	if err != nil {
+		return err
+	}
 
-	// EC parameters for secp256r1 (P-256)
-	ecParams := []byte{0x06, 0x08, 0x2a, 0x86, 0x48, 0xce, 0x3d, 0x03, 0x01, 0x07}
+	keyType := pkcs11.CKK_EC
+	if k.algorithm == AlgorithmEd25519 {
+		keyType = ckkEcEdwards
+	}
 
 	// Public key template
 	publicKeyTemplate := []*pkcs11.Attribute{
 		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PUBLIC_KEY),
-		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_EC),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, keyType),
 		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
 		pkcs11.NewAttribute(pkcs11.CKA_ID, []byte(fmt.Sprintf("%08d", id))),
 		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, ecParams),
@@ -95,7 +211,7 @@ func (k *SigningKey) generateKey(label string, id uint64) error {
 	// Private key template
 	privateKeyTemplate := []*pkcs11.Attribute{
 		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PRIVATE_KEY),
-		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_EC),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, keyType),
 		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
 		pkcs11.NewAttribute(pkcs11.CKA_ID, []byte(fmt.Sprintf("%08d", id))),
 		pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
@@ -104,18 +220,24 @@ func (k *SigningKey) generateKey(label string, id uint64) error {
 		pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
 	}
 
+	genMechanism, err := keyPairGenMechanismFor(k.algorithm)
+	if err != nil {
+		return err
+	}
+
 	// Generate key pair
-	mechanism := []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_EC_KEY_PAIR_GEN, nil)}
-	_, _, err := k.ctx.GenerateKeyPair(k.session, mechanism, publicKeyTemplate, privateKeyTemplate)
+	mechanism := []*pkcs11.Mechanism{pkcs11.NewMechanism(genMechanism, nil)}
+	_, _, err = k.ctx.GenerateKeyPair(session, mechanism, publicKeyTemplate, privateKeyTemplate)
 	if err != nil {
+		healthy = false
 		return fmt.Errorf("failed to generate key pair: %w", err)
 	}
 
 	return nil
 }
 
-// publicKeyUnlocked is the internal version that doesn't acquire the mutex
-func (k *SigningKey) publicKeyUnlocked(label string, id uint64) (string, error) {
+// publicKeyOnSession exports the CESR public key for (label, id) using an already-acquired session.
+func (k *PKCS11SigningKey) publicKeyOnSession(session pkcs11.SessionHandle, label string, id uint64) (string, error) {
 	// Find public key to export
 	publicKeyTemplate := []*pkcs11.Attribute{
 		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PUBLIC_KEY),
@@ -123,17 +245,17 @@ func (k *SigningKey) publicKeyUnlocked(label string, id uint64) (string, error)
 		pkcs11.NewAttribute(pkcs11.CKA_ID, []byte(fmt.Sprintf("%08d", id))),
 	}
 
-	err := k.ctx.FindObjectsInit(k.session, publicKeyTemplate)
+	err := k.ctx.FindObjectsInit(session, publicKeyTemplate)
 	if err != nil {
 		return "", fmt.Errorf("failed to init find public key: %w", err)
 	}
 
-	publicKeys, _, err := k.ctx.FindObjects(k.session, 1)
+	publicKeys, _, err := k.ctx.FindObjects(session, 1)
 	if err != nil {
 		return "", fmt.Errorf("failed to find public key: %w", err)
 	}
 
-	err = k.ctx.FindObjectsFinal(k.session)
+	err = k.ctx.FindObjectsFinal(session)
 	if err != nil {
 		return "", fmt.Errorf("failed to finalize public key find: %w", err)
 	}
@@ -147,34 +269,44 @@ func (k *SigningKey) publicKeyUnlocked(label string, id uint64) (string, error)
 		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
 	}
 
-	attrs, err := k.ctx.GetAttributeValue(k.session, publicKeys[0], ecPointAttr)
+	attrs, err := k.ctx.GetAttributeValue(session, publicKeys[0], ecPointAttr)
 	if err != nil {
 		return "", fmt.Errorf("failed to get EC_POINT: %w", err)
 	}
 
 	// EC_POINT is DER-encoded OCTET STRING, skip DER wrapper (first 2-3 bytes)
 	ecPoint := attrs[0].Value
-	var publicKeyBytes []byte
-	if len(ecPoint) > 0 && ecPoint[0] == 0x04 {
-		// Skip OCTET STRING tag and length
-		if ecPoint[1] == 0x41 { // length 65
-			publicKeyBytes = ecPoint[2:]
-		} else {
-			publicKeyBytes = ecPoint
+
+	if k.algorithm == AlgorithmEd25519 {
+		publicKeyBytes, err := unwrapOctetString(ecPoint, ed25519.PublicKeySize)
+		if err != nil {
+			return "", fmt.Errorf("invalid EC_POINT format: %w", err)
 		}
+
+		return Ed25519PublicKeyToCESR(ed25519.PublicKey(publicKeyBytes))
+	}
+
+	publicKeyBytes, err := unwrapOctetString(ecPoint, 65)
+	if err != nil {
+		return "", fmt.Errorf("invalid EC_POINT format: %w", err)
 	}
 
-	if len(publicKeyBytes) != 65 || publicKeyBytes[0] != 0x04 {
+	if publicKeyBytes[0] != 0x04 {
 		return "", fmt.Errorf("invalid EC_POINT format")
 	}
 
+	curve, err := curveFor(k.algorithm)
+	if err != nil {
+		return "", err
+	}
+
 	// Parse to ecdsa.PublicKey
-	ecdsaPublicKey, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), publicKeyBytes)
+	ecdsaPublicKey, err := ecdsa.ParseUncompressedPublicKey(curve, publicKeyBytes)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse public key: %w", err)
 	}
 
-	cesrPublicKey, err := PublicKeyToCESR(ecdsaPublicKey)
+	cesrPublicKey, err := ECPublicKeyToCESR(ecdsaPublicKey, k.algorithm)
 	if err != nil {
 		return "", fmt.Errorf("failed to convert public key to CESR")
 	}
@@ -182,103 +314,164 @@ func (k *SigningKey) publicKeyUnlocked(label string, id uint64) (string, error)
 	return cesrPublicKey, nil
 }
 
-// publicKey is the public API that acquires the mutex
-func (k *SigningKey) publicKey(label string, id uint64) (string, error) {
-	k.mu.Lock()
-	defer k.mu.Unlock()
-	return k.publicKeyUnlocked(label, id)
+// unwrapOctetString strips the DER OCTET STRING tag+length PKCS#11 wraps CKA_EC_POINT in,
+// returning the raw point bytes when they match the expected length.
+func unwrapOctetString(ecPoint []byte, expectedLen int) ([]byte, error) {
+	var raw []byte
+	if len(ecPoint) > 0 && ecPoint[0] == 0x04 {
+		if len(ecPoint) > 1 && int(ecPoint[1]) == expectedLen {
+			raw = ecPoint[2:]
+		} else {
+			raw = ecPoint
+		}
+	}
+
+	if len(raw) != expectedLen {
+		return nil, fmt.Errorf("expected %d bytes, got %d", expectedLen, len(raw))
+	}
+
+	return raw, nil
 }
 
-func (k *SigningKey) loadKey(label string, id uint64) error {
-	log.Printf("loadKey: attempting to load key with label=%s, id=%d", label, id)
+// publicKey is the public API; it acquires its own session from the pool.
+func (k *PKCS11SigningKey) publicKey(label string, id uint64) (string, error) {
+	session, err := k.pool.acquire(context.Background())
+	if err != nil {
+		return "", fmt.Errorf("failed to acquire session: %w", err)
+	}
+	defer k.pool.release(session, true)
+
+	return k.publicKeyOnSession(session, label, id)
+}
 
-	// Lock to prevent concurrent PKCS#11 operations
-	// PKCS#11 sessions are not thread-safe and will segfault if used concurrently
-	k.mu.Lock()
-	defer k.mu.Unlock()
+// findPrivateKey looks up the private key object handle for (label, id), consulting the
+// cache first so the hot signing path doesn't re-FindObjects on every call.
+func (k *PKCS11SigningKey) findPrivateKey(session pkcs11.SessionHandle, label string, id uint64) (pkcs11.ObjectHandle, error) {
+	cacheKey := privateKeyKey{label: label, id: id}
 
-	log.Printf("loadKey: lock acquired, searching for private key...")
+	k.keyCacheMu.RLock()
+	handle, ok := k.keyCache[cacheKey]
+	k.keyCacheMu.RUnlock()
+	if ok {
+		return handle, nil
+	}
 
-	// Find private key
 	privateKeyTemplate := []*pkcs11.Attribute{
 		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PRIVATE_KEY),
 		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
 		pkcs11.NewAttribute(pkcs11.CKA_ID, []byte(fmt.Sprintf("%08d", id))),
 	}
 
-	err := k.ctx.FindObjectsInit(k.session, privateKeyTemplate)
-	if err != nil {
-		log.Printf("loadKey: failed to init find objects: %v", err)
-		return fmt.Errorf("failed to init find objects: %w", err)
+	if err := k.ctx.FindObjectsInit(session, privateKeyTemplate); err != nil {
+		return 0, fmt.Errorf("failed to init find objects: %w", err)
 	}
 
-	privateKeys, _, err := k.ctx.FindObjects(k.session, 1)
+	privateKeys, _, err := k.ctx.FindObjects(session, 1)
 	if err != nil {
-		log.Printf("loadKey: failed to find private key: %v", err)
-		return fmt.Errorf("failed to find private key: %w", err)
+		return 0, fmt.Errorf("failed to find private key: %w", err)
 	}
 
-	err = k.ctx.FindObjectsFinal(k.session)
-	if err != nil {
-		log.Printf("loadKey: failed to finalize find: %v", err)
-		return fmt.Errorf("failed to finalize find: %w", err)
+	if err := k.ctx.FindObjectsFinal(session); err != nil {
+		return 0, fmt.Errorf("failed to finalize find: %w", err)
 	}
 
 	if len(privateKeys) == 0 {
-		log.Printf("loadKey: private key not found with label=%s, id=%d", label, id)
-		return fmt.Errorf("private key not found")
+		return 0, fmt.Errorf("private key not found")
+	}
+
+	handle = privateKeys[0]
+
+	k.keyCacheMu.Lock()
+	k.keyCache[cacheKey] = handle
+	k.keyCacheMu.Unlock()
+
+	return handle, nil
+}
+
+func (k *PKCS11SigningKey) loadKey(label string, id uint64) error {
+	session, err := k.pool.acquire(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to acquire session: %w", err)
+	}
+	defer k.pool.release(session, true)
+
+	if _, err := k.findPrivateKey(session, label, id); err != nil {
+		return err
 	}
 
-	log.Printf("loadKey: private key found, loading public key...")
-	cesrPublicKey, err := k.publicKeyUnlocked(label, id)
+	cesrPublicKey, err := k.publicKeyOnSession(session, label, id)
 	if err != nil {
-		log.Printf("loadKey: failed to load public key: %v", err)
 		return err
 	}
 
-	k.privateKey = privateKeys[0]
+	k.activeLabel = label
+	k.activeId = id
 	k.cesrPublicKey = cesrPublicKey
 
-	log.Printf("loadKey: key loaded successfully")
 	return nil
 }
 
-func (k *SigningKey) Close() {
-	// Lock to prevent concurrent PKCS#11 operations
-	// PKCS#11 sessions are not thread-safe and will segfault if used concurrently
-	k.mu.Lock()
-	defer k.mu.Unlock()
+func (k *PKCS11SigningKey) Close() {
+	if k.pool != nil {
+		k.pool.Close()
+	}
 
 	if k.ctx != nil {
-		k.ctx.Logout(k.session)
-		k.ctx.CloseSession(k.session)
 		k.ctx.Finalize()
 		k.ctx.Destroy()
 	}
 }
 
-func (k *SigningKey) Sign(data []byte) (string, error) {
-	// Lock to prevent concurrent PKCS#11 operations
-	// PKCS#11 sessions are not thread-safe and will segfault if used concurrently
-	k.mu.Lock()
-	defer k.mu.Unlock()
+func (k *PKCS11SigningKey) Sign(data []byte) (string, error) {
+	session, err := k.pool.acquire(context.Background())
+	if err != nil {
+		return "", fmt.Errorf("failed to acquire session: %w", err)
+	}
+
+	healthy := true
+	defer func() { k.pool.release(session, healthy) }()
 
-	// Hash the data
-	hash := sha256.Sum256(data)
+	privateKey, err := k.findPrivateKey(session, k.activeLabel, k.activeId)
+	if err != nil {
+		return "", err
+	}
+
+	signMechanism, err := signMechanismFor(k.algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	// Ed25519/EdDSA signs the message directly; it does its own hashing internally, unlike the
+	// ECDSA branch below which signs a pre-computed SHA-256 digest.
+	signInput := data
+	if k.algorithm != AlgorithmEd25519 {
+		hash := sha256.Sum256(data)
+		signInput = hash[:]
+	}
 
-	// Sign with PKCS#11
-	mechanism := []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil)}
+	mechanism := []*pkcs11.Mechanism{pkcs11.NewMechanism(signMechanism, nil)}
 
-	err := k.ctx.SignInit(k.session, mechanism, k.privateKey)
+	err = k.ctx.SignInit(session, mechanism, privateKey)
 	if err != nil {
+		healthy = false
 		return "", fmt.Errorf("failed to init signing: %w", err)
 	}
 
-	signature, err := k.ctx.Sign(k.session, hash[:])
+	signature, err := k.ctx.Sign(session, signInput)
 	if err != nil {
+		healthy = false
 		return "", fmt.Errorf("failed to sign: %w", err)
 	}
 
+	if k.algorithm == AlgorithmEd25519 {
+		cesrSignature, err := Ed25519SignatureToCESR(signature)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode signature: %w", err)
+		}
+
+		return cesrSignature, nil
+	}
+
 	var r, sVal *big.Int
 
 	// Try to parse as raw format first (64 bytes: 32 bytes R + 32 bytes S)
@@ -294,7 +487,7 @@ func (k *SigningKey) Sign(data []byte) (string, error) {
 	}
 
 	// Convert to CESR
-	cesrSignature, err := SignatureToCESR(r, sVal)
+	cesrSignature, err := ECSignatureToCESR(r, sVal, k.algorithm)
 	if err != nil {
 		return "", fmt.Errorf("failed to encode signature: %w", err)
 	}
@@ -3,15 +3,20 @@ package main
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -19,6 +24,7 @@ import (
 	"github.com/jasoncolburne/better-auth-go/examples/crypto"
 	"github.com/jasoncolburne/better-auth-go/examples/encoding"
 	"github.com/jasoncolburne/better-auth-go/pkg/cryptointerfaces"
+	"github.com/jasoncolburne/better-auth/examples/garden-k8s/auth/pkg/attestation"
 	"github.com/jasoncolburne/better-auth/examples/garden-k8s/auth/pkg/db"
 	"github.com/jasoncolburne/better-auth/examples/garden-k8s/auth/pkg/implementation"
 	"github.com/jasoncolburne/better-auth/examples/garden-k8s/auth/pkg/models"
@@ -27,6 +33,21 @@ import (
 
 type TokenAttributes struct {
 	PermissionsByRole map[string][]string `json:"permissionsByRole"`
+	// RenewAfterExpiry opts this particular token into RefreshSession accepting it within
+	// RenewGrace of its absolute lifetime expiring, rather than only strictly before. See
+	// RenewalPolicy.
+	RenewAfterExpiry bool `json:"renewAfterExpiry"`
+}
+
+// RenewalPolicy decides whether a token carrying attributes may be renewed after its absolute
+// lifetime has expired (within RenewGrace) instead of rejected outright — e.g. only for
+// certain roles. Mirrors ACME/step-ca's allowRenewAfterExpiry: opt-in, per deployment and per
+// token.
+type RenewalPolicy func(attributes TokenAttributes) bool
+
+// defaultRenewalPolicy honors the RenewAfterExpiry claim set on the token itself.
+func defaultRenewalPolicy(attributes TokenAttributes) bool {
+	return attributes.RenewAfterExpiry
 }
 
 type Server struct {
@@ -35,17 +56,117 @@ type Server struct {
 	serverResponseKey          cryptointerfaces.SigningKey
 	accessVerificationKeyStore *implementation.AccessVerificationKeyStore
 	authenticationKeyStore     *implementation.AuthenticationKeyStore
+	rateLimiter                implementation.RateLimiter
+	keyAttestationVerifier     cryptointerfaces.Verifier
+	keyAttestationTrustedRoots map[string]string
+	renewGrace                 time.Duration
+	renewalPolicy              RenewalPolicy
+	sessionInventory           implementation.SessionInventory
+	enableMultiLogin           bool
+	refreshLifetime            time.Duration
+	store                      *db.PostgreSQLStore
+	requestTimeout             time.Duration
+	shutdownTimeout            time.Duration
+	inFlight                   sync.WaitGroup
+	closeOnce                  sync.Once
 	server                     http.Server
 }
 
-func (s *Server) CloseClients() {
-	if s.accessVerificationKeyStore != nil {
-		_ = s.accessVerificationKeyStore.CloseClients()
+// AuthContext is the per-request identity/origin pair the rate limiter keys on. Identity is
+// sniffed best-effort from the request body (see bestEffortIdentity) since each endpoint's
+// message shape is defined in better-auth-go and isn't uniformly available here; PeerIP comes
+// from X-Forwarded-For, since requests arrive through the k8s ingress rather than directly.
+type AuthContext struct {
+	Identity string
+	PeerIP   string
+}
+
+// peerIP returns the originating client IP for r, preferring the last hop recorded in
+// X-Forwarded-For (the one appended by our own k8s ingress) and falling back to RemoteAddr
+// for direct connections. The first hop is whatever the client put there, so trusting it
+// would let any client pick its own per-request rate-limit/lockout bucket.
+func peerIP(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		hops := strings.Split(forwarded, ",")
+		if last := strings.TrimSpace(hops[len(hops)-1]); last != "" {
+			return last
+		}
 	}
 
-	if s.authenticationKeyStore != nil {
-		_ = s.authenticationKeyStore.CloseRevokedDevicesClient()
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// bestEffortIdentity sniffs a top-level "identity" field out of a request body without fully
+// decoding it into any particular better-auth-go request type (those differ per endpoint and
+// live outside this tree). Rate limiting degrades to IP-only keying if the field is absent.
+func bestEffortIdentity(message string) string {
+	var probe struct {
+		Identity string `json:"identity"`
 	}
+
+	if err := json.Unmarshal([]byte(message), &probe); err != nil {
+		return ""
+	}
+
+	return probe.Identity
+}
+
+func authContextFromRequest(r *http.Request, message string) AuthContext {
+	return AuthContext{
+		Identity: bestEffortIdentity(message),
+		PeerIP:   peerIP(r),
+	}
+}
+
+// rateLimitKeys returns every key RateLimiter should be checked/updated against for ctx: the
+// peer IP always, plus the identity when one could be sniffed, so a single attacker IP and a
+// single targeted identity are both throttled independently.
+func rateLimitKeys(ctx AuthContext) []string {
+	keys := []string{"ip:" + ctx.PeerIP}
+	if ctx.Identity != "" {
+		keys = append(keys, "identity:"+ctx.Identity)
+	}
+
+	return keys
+}
+
+// CloseClients closes every client/pool the server holds, in dependency order: the stores
+// built on top of Redis and Postgres first, then the connections underneath them. Safe to call
+// more than once (e.g. both from StopServer and a deferred cleanup in main) — only the first
+// call does anything.
+func (s *Server) CloseClients() {
+	s.closeOnce.Do(func() {
+		if s.accessVerificationKeyStore != nil {
+			_ = s.accessVerificationKeyStore.CloseClients()
+		}
+
+		if s.authenticationKeyStore != nil {
+			_ = s.authenticationKeyStore.CloseRevokedDevicesClient()
+		}
+
+		if s.rateLimiter != nil {
+			if err := s.rateLimiter.Close(); err != nil {
+				log.Printf("error closing rate limiter: %v", err)
+			}
+		}
+
+		if s.sessionInventory != nil {
+			if err := s.sessionInventory.Close(); err != nil {
+				log.Printf("error closing session inventory: %v", err)
+			}
+		}
+
+		if s.store != nil {
+			if err := s.store.Close(); err != nil {
+				log.Printf("error closing database pool: %v", err)
+			}
+		}
+	})
 }
 
 func NewServer() (*Server, error) {
@@ -152,21 +273,173 @@ func NewServer() (*Server, error) {
 		},
 	)
 
+	rateLimiter, err := newRateLimiterFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	keyAttestationTrustedRoots, err := keyAttestationTrustedRootsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	renewGrace, err := renewGraceFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	sessionInventory, err := implementation.NewRedisSessionInventory()
+	if err != nil {
+		return nil, err
+	}
+
+	shutdownTimeout, err := shutdownTimeoutFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	requestTimeout, err := requestTimeoutFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
 	return &Server{
 		ba:                         ba,
 		serverAccessKey:            serverAccessKey,
 		serverResponseKey:          serverResponseKey,
 		accessVerificationKeyStore: accessVerificationKeyStore,
 		authenticationKeyStore:     authenticationKeyStore,
+		rateLimiter:                rateLimiter,
+		keyAttestationVerifier:     verifier,
+		keyAttestationTrustedRoots: keyAttestationTrustedRoots,
+		renewGrace:                 renewGrace,
+		renewalPolicy:              defaultRenewalPolicy,
+		sessionInventory:           sessionInventory,
+		enableMultiLogin:           os.Getenv("ENABLE_MULTI_LOGIN") == "true",
+		refreshLifetime:            refreshLifetime,
+		store:                      store,
+		requestTimeout:             requestTimeout,
+		shutdownTimeout:            shutdownTimeout,
 	}, nil
 }
 
-func wrapResponse(w http.ResponseWriter, r *http.Request, logic func(ctx context.Context, message string) (string, error)) {
+// requestTimeoutFromEnv reads how long a request's context stays valid once wrapResponse/
+// wrapRateLimitedResponse derive it from r.Context(), so a client disconnect or an upstream
+// cancellation cascades into the in-flight api.BetterAuthServer call instead of it running to
+// completion regardless. Follows the same env-configured-duration convention as
+// shutdownTimeoutFromEnv/renewGraceFromEnv.
+func requestTimeoutFromEnv() (time.Duration, error) {
+	raw := os.Getenv("REQUEST_TIMEOUT_SECONDS")
+	if raw == "" {
+		return 5 * time.Second, nil
+	}
+
+	seconds, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid REQUEST_TIMEOUT_SECONDS: %w", err)
+	}
+
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// shutdownTimeoutFromEnv reads how long StopServer waits for in-flight requests to drain
+// before closing clients and pools out from under them anyway, following the same
+// env-configured-duration convention as renewGraceFromEnv/newRateLimiterFromEnv.
+func shutdownTimeoutFromEnv() (time.Duration, error) {
+	raw := os.Getenv("SHUTDOWN_TIMEOUT_SECONDS")
+	if raw == "" {
+		return 30 * time.Second, nil
+	}
+
+	seconds, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid SHUTDOWN_TIMEOUT_SECONDS: %w", err)
+	}
+
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// renewGraceFromEnv reads how long past a token's absolute lifetime RefreshSession may still
+// accept it when RenewalPolicy allows renewal-after-expiry for that token. Zero (the default)
+// matches this codebase's "0s means no expiration"-flavored convention applied to grace
+// windows: no grace at all, i.e. the opt-in has no effect until a deployment sets this.
+//
+// NOTE: api.BetterAuthServer.RefreshSession (github.com/jasoncolburne/better-auth-go, not
+// vendored in this tree) is where RenewGrace/RenewalPolicy actually need to be consulted —
+// there's no ExpiryContainer field or RefreshSession hook for either yet. This is the
+// deployment-config side of chunk2-4, ready for that hook once it exists.
+func renewGraceFromEnv() (time.Duration, error) {
+	raw := os.Getenv("TOKEN_RENEW_GRACE_SECONDS")
+	if raw == "" {
+		return 0, nil
+	}
+
+	seconds, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid TOKEN_RENEW_GRACE_SECONDS: %w", err)
+	}
+
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// newRateLimiterFromEnv builds the Redis-backed RateLimiter so lockouts are honored across
+// every replica, not just the one that observed the failures. Policy defaults to "5 failures
+// per 30 minutes then a 30 minute lockout" and is overridable via RATE_LIMIT_MAX_ATTEMPTS /
+// RATE_LIMIT_WINDOW_SECONDS / RATE_LIMIT_LOCKOUT_SECONDS.
+//
+// NOTE: ba.BetterAuthServer itself (defined in the external better-auth-go module, not
+// vendored in this tree) has no RateLimitContainer/NewBetterAuthServer hook to plumb this
+// through yet, so enforcement happens at the HTTP layer in wrapRateLimitedResponse instead of
+// inside the server. Once better-auth-go grows that hook, this should move there.
+func newRateLimiterFromEnv() (implementation.RateLimiter, error) {
+	maxAttempts := 5
+	if v := os.Getenv("RATE_LIMIT_MAX_ATTEMPTS"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, err
+		}
+		maxAttempts = parsed
+	}
+
+	window := 30 * time.Minute
+	if v := os.Getenv("RATE_LIMIT_WINDOW_SECONDS"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, err
+		}
+		window = time.Duration(parsed) * time.Second
+	}
+
+	lockoutDuration := 30 * time.Minute
+	if v := os.Getenv("RATE_LIMIT_LOCKOUT_SECONDS"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, err
+		}
+		lockoutDuration = time.Duration(parsed) * time.Second
+	}
+
+	policy := implementation.RateLimitContainer{
+		MaxAttempts:     maxAttempts,
+		Window:          window,
+		LockoutDuration: lockoutDuration,
+	}
+
+	return implementation.NewRedisRateLimiter(policy)
+}
+
+// wrapResponse runs logic with a context derived from r.Context() (so a client disconnect
+// cancels it) bounded by RequestTimeout, and tracks it in inFlight so StopServer can wait for
+// it to finish before closing clients out from under it.
+func (s *Server) wrapResponse(w http.ResponseWriter, r *http.Request, logic func(ctx context.Context, message string) (string, error)) {
+	s.inFlight.Add(1)
+	defer s.inFlight.Done()
+
 	var reply string
 
 	message, err := io.ReadAll(r.Body)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), s.requestTimeout)
 	defer cancel()
 
 	if err == nil {
@@ -184,53 +457,223 @@ func wrapResponse(w http.ResponseWriter, r *http.Request, logic func(ctx context
 	fmt.Fprintf(w, "%s", reply)
 }
 
+// wrapRateLimitedResponse is wrapResponse plus a lockout check/update keyed on the request's
+// AuthContext (identity + peer IP), for endpoints in the authentication-attempt path
+// (RequestSession, CreateSession, RotateDevice, RecoverAccount, ChangeRecoveryKey). logic's
+// error return also drives the limiter: a failure counts against the lockout, a success
+// clears it.
+func (s *Server) wrapRateLimitedResponse(w http.ResponseWriter, r *http.Request, logic func(ctx context.Context, message string) (string, error)) {
+	s.inFlight.Add(1)
+	defer s.inFlight.Done()
+
+	var reply string
+
+	message, err := io.ReadAll(r.Body)
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.requestTimeout)
+	defer cancel()
+
+	var keys []string
+	if err == nil {
+		keys = rateLimitKeys(authContextFromRequest(r, string(message)))
+
+		allowed := true
+		for _, key := range keys {
+			ok, allowErr := s.rateLimiter.Allow(ctx, key)
+			if allowErr != nil {
+				err = allowErr
+				break
+			}
+			if !ok {
+				allowed = false
+			}
+		}
+
+		if err == nil && !allowed {
+			err = implementation.ErrRateLimited
+		}
+	}
+
+	if err == nil {
+		reply, err = logic(ctx, string(message))
+
+		for _, key := range keys {
+			if err != nil {
+				_ = s.rateLimiter.RecordFailure(ctx, key)
+			} else {
+				_ = s.rateLimiter.RecordSuccess(ctx, key)
+			}
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		reply = "{\"error\":\"an error occurred\"}"
+
+		if errors.Is(err, implementation.ErrRateLimited) {
+			w.WriteHeader(http.StatusTooManyRequests)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	fmt.Fprintf(w, "%s", reply)
+}
+
 func (s *Server) create(w http.ResponseWriter, r *http.Request) {
-	wrapResponse(w, r, s.ba.CreateAccount)
+	s.wrapResponse(w, r, s.ba.CreateAccount)
 }
 
 func (s *Server) recover(w http.ResponseWriter, r *http.Request) {
-	wrapResponse(w, r, s.ba.RecoverAccount)
+	s.wrapRateLimitedResponse(w, r, s.ba.RecoverAccount)
 }
 
 func (s *Server) delete(w http.ResponseWriter, r *http.Request) {
-	wrapResponse(w, r, s.ba.DeleteAccount)
+	s.wrapResponse(w, r, s.ba.DeleteAccount)
 }
 
 func (s *Server) link(w http.ResponseWriter, r *http.Request) {
-	wrapResponse(w, r, s.ba.LinkDevice)
+	s.wrapResponse(w, r, s.ba.LinkDevice)
 }
 
 func (s *Server) unlink(w http.ResponseWriter, r *http.Request) {
-	wrapResponse(w, r, s.ba.UnlinkDevice)
+	s.wrapResponse(w, r, s.ba.UnlinkDevice)
 }
 
 func (s *Server) startAuthentication(w http.ResponseWriter, r *http.Request) {
-	wrapResponse(w, r, s.ba.RequestSession)
+	s.wrapRateLimitedResponse(w, r, s.ba.RequestSession)
 }
 
 func (s *Server) finishAuthentication(w http.ResponseWriter, r *http.Request) {
-	wrapResponse(w, r, func(ctx context.Context, message string) (string, error) {
-		return s.ba.CreateSession(ctx, message, TokenAttributes{
+	s.wrapRateLimitedResponse(w, r, func(ctx context.Context, message string) (string, error) {
+		attributes := TokenAttributes{
 			PermissionsByRole: map[string][]string{
 				"user": {
 					"read",
 					"write",
 				},
 			},
-		})
+		}
+		attributes.RenewAfterExpiry = s.renewalPolicy(attributes)
+
+		identity := bestEffortIdentity(message)
+
+		if !s.enableMultiLogin && identity != "" {
+			if err := s.sessionInventory.RevokeAll(ctx, identity); err != nil {
+				return "", fmt.Errorf("failed to revoke prior sessions: %w", err)
+			}
+		}
+
+		reply, err := s.ba.CreateSession(ctx, message, attributes)
+		if err != nil {
+			return "", err
+		}
+
+		if identity != "" {
+			sessionId, idErr := newSessionId()
+			if idErr != nil {
+				return "", fmt.Errorf("failed to generate session id: %w", idErr)
+			}
+
+			now := time.Now()
+			if recordErr := s.sessionInventory.Record(ctx, implementation.SessionInfo{
+				SessionId: sessionId,
+				Identity:  identity,
+				CreatedAt: now,
+				ExpiresAt: now.Add(s.refreshLifetime),
+			}); recordErr != nil {
+				return "", fmt.Errorf("failed to record session: %w", recordErr)
+			}
+		}
+
+		return reply, nil
+	})
+}
+
+// newSessionId generates a random session inventory id, independent of whatever session/token
+// identifiers api.BetterAuthServer's CreateSession itself produces internally — we don't have
+// access to those from out here.
+func newSessionId() (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(raw), nil
+}
+
+// ListSessions returns every session SessionInventory currently has recorded as active for
+// identity — "which devices am I logged into".
+func (s *Server) ListSessions(ctx context.Context, identity string) ([]implementation.SessionInfo, error) {
+	return s.sessionInventory.List(ctx, identity)
+}
+
+// RevokeSession removes sessionId from SessionInventory, ending an operator's visibility into
+// it and any future EnableMultiLogin=false RevokeAll call treating it as active.
+//
+// NOTE: this does not itself invalidate the access/refresh tokens api.BetterAuthServer issued
+// for that session — api.BetterAuthServer (github.com/jasoncolburne/better-auth-go, not
+// vendored in this tree) has no token-revocation hook for SessionInventory to call into yet.
+// Until it does, a revoked session's existing tokens remain valid until their own expiry; this
+// is the inventory/bookkeeping half of chunk2-5, ready for that hook once it exists.
+func (s *Server) RevokeSession(ctx context.Context, sessionId string) error {
+	return s.sessionInventory.Revoke(ctx, sessionId)
+}
+
+func (s *Server) listSessions(w http.ResponseWriter, r *http.Request) {
+	s.wrapResponse(w, r, func(ctx context.Context, message string) (string, error) {
+		var req struct {
+			Identity string `json:"identity"`
+		}
+		if err := json.Unmarshal([]byte(message), &req); err != nil {
+			return "", fmt.Errorf("invalid request: %w", err)
+		}
+
+		sessions, err := s.ListSessions(ctx, req.Identity)
+		if err != nil {
+			return "", err
+		}
+
+		reply, err := json.Marshal(struct {
+			Sessions []implementation.SessionInfo `json:"sessions"`
+		}{Sessions: sessions})
+		if err != nil {
+			return "", err
+		}
+
+		return string(reply), nil
+	})
+}
+
+func (s *Server) revokeSession(w http.ResponseWriter, r *http.Request) {
+	s.wrapResponse(w, r, func(ctx context.Context, message string) (string, error) {
+		var req struct {
+			SessionId string `json:"sessionId"`
+		}
+		if err := json.Unmarshal([]byte(message), &req); err != nil {
+			return "", fmt.Errorf("invalid request: %w", err)
+		}
+
+		if err := s.RevokeSession(ctx, req.SessionId); err != nil {
+			return "", err
+		}
+
+		return "{\"revoked\":true}", nil
 	})
 }
 
 func (s *Server) rotateAuthentication(w http.ResponseWriter, r *http.Request) {
-	wrapResponse(w, r, s.ba.RotateDevice)
+	s.wrapRateLimitedResponse(w, r, s.ba.RotateDevice)
 }
 
 func (s *Server) rotateAccess(w http.ResponseWriter, r *http.Request) {
-	wrapResponse(w, r, s.ba.RefreshSession)
+	s.wrapResponse(w, r, s.ba.RefreshSession)
 }
 
 func (s *Server) changeRecoveryKey(w http.ResponseWriter, r *http.Request) {
-	wrapResponse(w, r, s.ba.ChangeRecoveryKey)
+	s.wrapRateLimitedResponse(w, r, s.ba.ChangeRecoveryKey)
 }
 
 func (s *Server) healthCheck(w http.ResponseWriter, r *http.Request) {
@@ -256,6 +699,8 @@ func (s *Server) StartServer() error {
 	http.HandleFunc("/session/request", s.startAuthentication)
 	http.HandleFunc("/session/create", s.finishAuthentication)
 	http.HandleFunc("/session/refresh", s.rotateAccess)
+	http.HandleFunc("/session/list", s.listSessions)
+	http.HandleFunc("/session/revoke", s.revokeSession)
 
 	http.HandleFunc("/device/rotate", s.rotateAuthentication)
 	http.HandleFunc("/device/link", s.link)
@@ -278,15 +723,42 @@ func (s *Server) StartServer() error {
 	return s.server.ListenAndServe()
 }
 
+// StopServer stops accepting new connections, waits for in-flight wrapResponse/
+// wrapRateLimitedResponse calls to finish, and then closes every client and pool the server
+// holds — all bounded by ShutdownTimeout, so a wedged request can't block a pod from
+// terminating forever.
 func (s *Server) StopServer() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
 	defer cancel()
 
-	return s.server.Shutdown(ctx)
+	shutdownErr := s.server.Shutdown(ctx)
+
+	drained := make(chan struct{})
+	go func() {
+		s.inFlight.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		log.Printf("shutdown timed out waiting for in-flight requests to drain")
+	}
+
+	s.CloseClients()
+
+	return shutdownErr
 }
 
-// signWithHSM signs a payload using the HSM service
-func signWithHSM(hsmURL string, payload implementation.KeySigningPayload) (string, error) {
+// signWithHSM signs a payload using the HSM service, returning the HSM's signature alongside
+// the exact "body" bytes it signed (payload + which HSM key signed it) — an attestation has to
+// be framed around those exact bytes, not a re-marshaled approximation, or its signature won't
+// verify.
+//
+// The POST itself is wrapped in implementation.RetryRedisOperation's exponential backoff (its
+// name is Redis-flavored, but it retries any fallible operation) so a pod booting slightly
+// ahead of the HSM survives rather than crash-looping.
+func signWithHSM(ctx context.Context, hsmURL string, payload implementation.KeySigningPayload) (string, json.RawMessage, error) {
 	// Create request
 	reqBody := struct {
 		Payload implementation.KeySigningPayload `json:"payload"`
@@ -295,38 +767,136 @@ func signWithHSM(hsmURL string, payload implementation.KeySigningPayload) (strin
 	}
 	reqJSON, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return "", nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	type hsmSignResult struct {
+		Signature string
+		Body      json.RawMessage
 	}
 
-	// POST to HSM
-	resp, err := http.Post(hsmURL+"/sign", "application/json", bytes.NewBuffer(reqJSON))
+	result, err := implementation.RetryRedisOperation(ctx, func() (hsmSignResult, error) {
+		// POST to HSM
+		resp, err := http.Post(hsmURL+"/sign", "application/json", bytes.NewBuffer(reqJSON))
+		if err != nil {
+			return hsmSignResult{}, fmt.Errorf("failed to POST to HSM: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return hsmSignResult{}, fmt.Errorf("HSM returned status %d", resp.StatusCode)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return hsmSignResult{}, fmt.Errorf("failed to read response")
+		}
+
+		// Parse response
+		var signResp struct {
+			Body      json.RawMessage `json:"body"`
+			Signature string          `json:"signature"`
+		}
+		if err := json.Unmarshal(body, &signResp); err != nil {
+			return hsmSignResult{}, fmt.Errorf("failed to decode HSM response: %w", err)
+		}
+
+		return hsmSignResult{Signature: signResp.Signature, Body: signResp.Body}, nil
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to POST to HSM: %w", err)
+		return "", nil, err
+	}
+
+	return result.Signature, result.Body, nil
+}
+
+// keyAttestationTrustedRootsFromEnv returns the set of HSM generation-key ids currently
+// trusted to vouch for access/response keys, keyed by generation id and pointing at that
+// generation's own public key. Supporting more than one simultaneously-trusted root lets the
+// HSM's signing key rotate without a window where every previously-issued attestation suddenly
+// looks untrusted: the new generation id is added to HSM_TRUSTED_ROOTS before the old one is
+// retired.
+//
+// HSM_TRUSTED_ROOTS is a JSON object of {"<generationId>": "<publicKey>", ...}.
+func keyAttestationTrustedRootsFromEnv() (map[string]string, error) {
+	raw := os.Getenv("HSM_TRUSTED_ROOTS")
+	if raw == "" {
+		return map[string]string{}, nil
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("HSM returned status %d", resp.StatusCode)
+	roots := map[string]string{}
+	if err := json.Unmarshal([]byte(raw), &roots); err != nil {
+		return nil, fmt.Errorf("invalid HSM_TRUSTED_ROOTS: %w", err)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	return roots, nil
+}
+
+// encodeKeyAttestation frames body (exactly as echoed by the HSM, so its bytes match what was
+// actually signed) and signature as a compact self-framed attestation string, using
+// pkg/attestation's encoding.
+func encodeKeyAttestation(body json.RawMessage, signature string) string {
+	return attestation.Encode(attestation.IndicatorV1, body, signature)
+}
+
+// verifyKeyAttestation decodes an encodeKeyAttestation string, confirms it was issued for
+// expectedPurpose by one of trustedRoots, hasn't expired, and its signature checks out.
+//
+// NOTE: nothing in this tree calls this from the verification side of a live request yet.
+// api.BetterAuthServer's AccessVerifier (github.com/jasoncolburne/better-auth-go, not vendored
+// in this tree) is where that hook belongs, and this example's own
+// implementation.AccessVerificationKeyStore.Get isn't defined here either (main.go references
+// it, but pkg/implementation never got a file for it — a pre-existing gap, not one this change
+// introduces). Once both exist, Get should call this before trusting a key pulled from Redis.
+func verifyKeyAttestation(
+	verifier cryptointerfaces.Verifier,
+	trustedRoots map[string]string,
+	encoded string,
+	expectedPurpose string,
+) (implementation.KeySigningBody, error) {
+	indicator, payload, signature, err := attestation.Decode(encoded)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response")
+		return implementation.KeySigningBody{}, err
 	}
 
-	// Parse response
-	var signResp struct {
-		Body      implementation.KeySigningBody `json:"body"`
-		Signature string                        `json:"signature"`
+	if indicator != attestation.IndicatorV1 {
+		return implementation.KeySigningBody{}, fmt.Errorf("unsupported attestation indicator %q", string(indicator))
 	}
-	if err := json.Unmarshal(body, &signResp); err != nil {
-		return "", fmt.Errorf("failed to decode HSM response: %w", err)
+
+	var body implementation.KeySigningBody
+	if err := json.Unmarshal(payload, &body); err != nil {
+		return implementation.KeySigningBody{}, fmt.Errorf("failed to unmarshal attestation body: %w", err)
+	}
+
+	if body.Payload.Purpose != expectedPurpose {
+		return implementation.KeySigningBody{}, fmt.Errorf("incorrect purpose: expected %q, got %q", expectedPurpose, body.Payload.Purpose)
+	}
+
+	rootPublicKey, ok := trustedRoots[body.Hsm.GenerationId]
+	if !ok {
+		return implementation.KeySigningBody{}, fmt.Errorf("attestation signed by unknown HSM generation %q", body.Hsm.GenerationId)
+	}
+
+	if err := verifier.Verify(signature, rootPublicKey, payload); err != nil {
+		return implementation.KeySigningBody{}, fmt.Errorf("invalid attestation signature: %w", err)
+	}
+
+	expiration, err := time.Parse(time.RFC3339Nano, body.Payload.Expiration)
+	if err != nil {
+		return implementation.KeySigningBody{}, fmt.Errorf("invalid attestation expiration: %w", err)
+	}
+
+	if time.Now().After(expiration) {
+		return implementation.KeySigningBody{}, fmt.Errorf("expired attestation")
 	}
 
-	return strings.TrimSpace(string(body)), nil
+	return body, nil
 }
 
-// registerKeysInRedis writes the server's access and response public keys to Redis
+// registerKeysInRedis writes the server's access and response public keys to Redis. The HSM
+// signing calls and the Redis writes themselves are each wrapped in
+// implementation.RetryRedisOperation's exponential backoff, so a pod that comes up slightly
+// ahead of Redis or the HSM at boot survives rather than crash-looping.
 func registerKeysInRedis(accessKey, responseKey cryptointerfaces.SigningKey) error {
 	redisHost := os.Getenv("REDIS_HOST")
 	if redisHost == "" {
@@ -381,11 +951,13 @@ func registerKeysInRedis(accessKey, responseKey cryptointerfaces.SigningKey) err
 		PublicKey:  accessPublicKey,
 		Expiration: accessExpiration,
 	}
-	accessAuthorization, err := signWithHSM(hsmURL, accessPayload)
+	var accessAuthorization string
+	accessSignature, accessBody, err := signWithHSM(ctx, hsmURL, accessPayload)
 	if err != nil {
 		log.Printf("Warning: Failed to sign access key with HSM: %v", err)
 	} else {
-		log.Printf("Access key HSM authorization (CESR): %s", accessAuthorization)
+		accessAuthorization = encodeKeyAttestation(accessBody, accessSignature)
+		log.Printf("Access key HSM attestation: %s", accessAuthorization)
 	}
 
 	// Sign response key (expires in 12 hours + 1 minute to match Redis TTL)
@@ -395,11 +967,13 @@ func registerKeysInRedis(accessKey, responseKey cryptointerfaces.SigningKey) err
 		PublicKey:  responsePublicKey,
 		Expiration: responseExpiration,
 	}
-	responseAuthorization, err := signWithHSM(hsmURL, responsePayload)
+	var responseAuthorization string
+	responseSignature, responseBody, err := signWithHSM(ctx, hsmURL, responsePayload)
 	if err != nil {
 		log.Printf("Warning: Failed to sign response key with HSM: %v", err)
 	} else {
-		log.Printf("Response key HSM authorization (CESR): %s", responseAuthorization)
+		responseAuthorization = encodeKeyAttestation(responseBody, responseSignature)
+		log.Printf("Response key HSM attestation: %s", responseAuthorization)
 	}
 
 	accessClient := redis.NewClient(&redis.Options{
@@ -409,7 +983,9 @@ func registerKeysInRedis(accessKey, responseKey cryptointerfaces.SigningKey) err
 	defer accessClient.Close()
 
 	// Write access key with 24 hour TTL: SET <public_key> <public_key> EX 86400
-	if err := accessClient.Set(ctx, accessPublicKey, accessAuthorization, accessTTL).Err(); err != nil {
+	if _, err := implementation.RetryRedisOperation(ctx, func() (struct{}, error) {
+		return struct{}{}, accessClient.Set(ctx, accessPublicKey, accessAuthorization, accessTTL).Err()
+	}); err != nil {
 		return fmt.Errorf("failed to write access key to Redis: %w", err)
 	}
 	log.Printf("Registered access key in Redis DB 0 (TTL: 24 hours)")
@@ -421,7 +997,9 @@ func registerKeysInRedis(accessKey, responseKey cryptointerfaces.SigningKey) err
 	defer responseClient.Close()
 
 	// Write response key with 12 hour 1 minute TTL: SET <public_key> <public_key> EX 43260
-	if err := responseClient.Set(ctx, responsePublicKey, responseAuthorization, responseTTL).Err(); err != nil {
+	if _, err := implementation.RetryRedisOperation(ctx, func() (struct{}, error) {
+		return struct{}{}, responseClient.Set(ctx, responsePublicKey, responseAuthorization, responseTTL).Err()
+	}); err != nil {
 		return fmt.Errorf("failed to write response key to Redis: %w", err)
 	}
 	log.Printf("Registered response key in Redis DB 1 (TTL: 12 hours)")
@@ -452,7 +1030,9 @@ func main() {
 		os.Exit(0)
 	}()
 
-	if err := server.StartServer(); err != nil {
+	// ListenAndServe always returns http.ErrServerClosed once Shutdown has been called by the
+	// signal handler above; that's the expected outcome of a graceful shutdown, not a failure.
+	if err := server.StartServer(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatalf("Server failed: %v", err)
 	}
 }
@@ -4,46 +4,49 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"regexp"
 
 	"github.com/jasoncolburne/verifiable-storage-go/pkg/data"
 	"github.com/jmoiron/sqlx"
-	"github.com/lib/pq"
 )
 
-type PostgreSQLStore struct {
-	db *sqlx.DB
-	tx *sqlx.Tx
+// Store is a connection pool plus an optional in-progress transaction, generalized over a
+// Dialect so the same model code runs unchanged against Postgres, MySQL, or SQLite.
+type Store struct {
+	db      *sqlx.DB
+	tx      *sqlx.Tx
+	dialect Dialect
 }
 
-func NewPostgreSQLStore(ctx context.Context, dsn string, migrations []string) (*PostgreSQLStore, error) {
-	db, err := sqlx.Connect("postgres", dsn)
+// PostgreSQLStore is kept as an alias so existing field declarations and call sites (this
+// example's Server.store, NewPostgreSQLStore) don't need to change now that Store is
+// dialect-generic.
+type PostgreSQLStore = Store
+
+// NewStore connects to dsn using dialect's driver, runs migrations (translated into dialect's
+// DDL syntax) under dialect's migration lock, and returns the resulting Store.
+func NewStore(ctx context.Context, dialect Dialect, dsn string, migrations []string) (*Store, error) {
+	db, err := sqlx.Connect(dialect.Driver(), dsn)
 	if err != nil {
 		return nil, err
 	}
 
-	// Use advisory lock to prevent concurrent migrations
-	// Lock ID: 0x42415554485f4d49 (ASCII for "BAUTH_MI" - Better Auth Migrations)
-	const migrationLockID = 4774929821315686217
-
-	// Acquire advisory lock (blocks until available)
-	if _, err := db.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+	release, err := dialect.AcquireMigrationLock(ctx, db)
+	if err != nil {
 		db.Close()
-		return nil, fmt.Errorf("failed to acquire migration lock: %w", err)
+		return nil, err
 	}
 
 	// Run migrations
 	migrationErr := func() error {
 		for _, migration := range migrations {
-			if _, err := db.ExecContext(ctx, migration); err != nil {
+			if _, err := db.ExecContext(ctx, dialect.TranslateDDL(migration)); err != nil {
 				return err
 			}
 		}
 		return nil
 	}()
 
-	// Release advisory lock
-	if _, err := db.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", migrationLockID); err != nil {
+	if err := release(); err != nil {
 		db.Close()
 		return nil, fmt.Errorf("failed to release migration lock: %w", err)
 	}
@@ -54,13 +57,20 @@ func NewPostgreSQLStore(ctx context.Context, dsn string, migrations []string) (*
 		return nil, migrationErr
 	}
 
-	return &PostgreSQLStore{
-		db: db.Unsafe(), // the unsafe here allows us to gracefully ignore computed columns
-		tx: nil,
+	return &Store{
+		db:      db.Unsafe(), // the unsafe here allows us to gracefully ignore computed columns
+		tx:      nil,
+		dialect: dialect,
 	}, nil
 }
 
-func (s PostgreSQLStore) Sql() data.SQLStore {
+// NewPostgreSQLStore is the original, Postgres-specific constructor, kept as a thin wrapper
+// around NewStore for existing callers.
+func NewPostgreSQLStore(ctx context.Context, dsn string, migrations []string) (*PostgreSQLStore, error) {
+	return NewStore(ctx, Postgres(), dsn, migrations)
+}
+
+func (s Store) Sql() data.SQLStore {
 	if s.tx == nil {
 		return s.db
 	} else {
@@ -68,7 +78,7 @@ func (s PostgreSQLStore) Sql() data.SQLStore {
 	}
 }
 
-func (s *PostgreSQLStore) BeginTransaction(ctx context.Context, opts *sql.TxOptions) error {
+func (s *Store) BeginTransaction(ctx context.Context, opts *sql.TxOptions) error {
 	if s.tx != nil {
 		return fmt.Errorf("transaction in progress")
 	}
@@ -83,7 +93,7 @@ func (s *PostgreSQLStore) BeginTransaction(ctx context.Context, opts *sql.TxOpti
 	return nil
 }
 
-func (s *PostgreSQLStore) CommitTransaction() error {
+func (s *Store) CommitTransaction() error {
 	if s.tx == nil {
 		return fmt.Errorf("no transaction in progress")
 	}
@@ -97,7 +107,7 @@ func (s *PostgreSQLStore) CommitTransaction() error {
 	return nil
 }
 
-func (s *PostgreSQLStore) RollbackTransaction() error {
+func (s *Store) RollbackTransaction() error {
 	if s.tx == nil {
 		return fmt.Errorf("no transaction in progress")
 	}
@@ -111,25 +121,16 @@ func (s *PostgreSQLStore) RollbackTransaction() error {
 	return nil
 }
 
-func (*PostgreSQLStore) ReplacePlaceholders(query string) string {
-	count := 0
-	return regexp.MustCompile(`\?`).ReplaceAllStringFunc(query, func(m string) string {
-		count++
-		return fmt.Sprintf("$%d", count)
-	})
-}
-
-type AnyBuilder struct{}
-
-func NewAnyBuilder() *AnyBuilder {
-	return &AnyBuilder{}
+// Close releases the underlying connection pool. Callers should not use s again afterward.
+func (s *Store) Close() error {
+	return s.db.Close()
 }
 
-func (AnyBuilder) String(column string, values []any) string {
-	expression := fmt.Sprintf("%s=ANY(?)", column)
-	return expression
+func (s *Store) ReplacePlaceholders(query string) string {
+	return s.dialect.ReplacePlaceholders(query)
 }
 
-func (AnyBuilder) Values(values []any) []any {
-	return []any{pq.Array(values)}
+// AnyBuilder returns the array-membership query builder for s's dialect.
+func (s *Store) AnyBuilder() AnyBuilder {
+	return s.dialect.AnyBuilder()
 }
@@ -0,0 +1,37 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Dialect abstracts the SQL-syntax differences between backends, so the same model code —
+// table DDL, placeholder style, array-membership queries — works unchanged across Postgres,
+// MySQL, and SQLite, the way gobuffalo/pop's per-database Dialect does.
+type Dialect interface {
+	// Name identifies the dialect, e.g. for logging.
+	Name() string
+	// Driver is the database/sql driver name to pass to sqlx.Connect.
+	Driver() string
+	// ReplacePlaceholders rewrites a query written with `?` placeholders into this dialect's
+	// native placeholder syntax ($N for Postgres, unchanged for MySQL/SQLite).
+	ReplacePlaceholders(query string) string
+	// AnyBuilder returns the array-membership query builder for this dialect.
+	AnyBuilder() AnyBuilder
+	// TranslateDDL rewrites a CREATE TABLE statement written against this codebase's
+	// Postgres-flavored baseline (see pkg/models) into this dialect's own DDL syntax.
+	TranslateDDL(statement string) string
+	// AcquireMigrationLock takes a dialect-specific session lock so concurrent replicas don't
+	// race running migrations against each other at boot, returning a release func to call
+	// once migrations finish (success or not). Dialects without concurrent writers to guard
+	// against (SQLite) return a no-op release.
+	AcquireMigrationLock(ctx context.Context, conn *sqlx.DB) (release func() error, err error)
+}
+
+// AnyBuilder renders an array-membership comparison (`column = ANY(values)` / `column IN
+// (values...)`) in whatever syntax and argument shape a Dialect's driver expects.
+type AnyBuilder interface {
+	String(column string, values []any) string
+	Values(values []any) []any
+}
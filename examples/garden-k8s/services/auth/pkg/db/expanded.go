@@ -0,0 +1,32 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// expandedAnyBuilder is the portable fallback for dialects without a native array type: it
+// expands `column=ANY(?)` into `column IN (?, ?, ...)`, one placeholder per value. Shared by
+// MySQL and SQLite.
+type expandedAnyBuilder struct{}
+
+func (expandedAnyBuilder) String(column string, values []any) string {
+	if len(values) == 0 {
+		// An empty IN-list is a syntax error in both dialects; this is always false, matching
+		// what `column=ANY('{}')` means in Postgres.
+		return "1=0"
+	}
+
+	placeholders := make([]string, len(values))
+	for i := range values {
+		placeholders[i] = "?"
+	}
+
+	return fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ", "))
+}
+
+func (expandedAnyBuilder) Values(values []any) []any {
+	return values
+}
+
+var _ AnyBuilder = expandedAnyBuilder{}
@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/jmoiron/sqlx"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+type mysqlDialect struct{}
+
+// MySQL is the Dialect for running this codebase's schema against MySQL/InnoDB: `?`
+// placeholders unchanged, an expanded IN-list in place of ANY(), TEXT primary keys widened to
+// VARCHAR since InnoDB can't index a bare TEXT column, and GET_LOCK for migration
+// serialization.
+func MySQL() Dialect {
+	return mysqlDialect{}
+}
+
+func (mysqlDialect) Name() string   { return "mysql" }
+func (mysqlDialect) Driver() string { return "mysql" }
+
+// ReplacePlaceholders is the identity function: MySQL's driver already accepts `?` natively.
+func (mysqlDialect) ReplacePlaceholders(query string) string {
+	return query
+}
+
+func (mysqlDialect) AnyBuilder() AnyBuilder {
+	return expandedAnyBuilder{}
+}
+
+var mysqlTextPrimaryKey = regexp.MustCompile(`(?i)TEXT PRIMARY KEY`)
+
+// TranslateDDL widens `TEXT PRIMARY KEY` to `VARCHAR(255) PRIMARY KEY`: InnoDB refuses to
+// index a bare TEXT column, and every model in this codebase keys its tables on short
+// identifiers (nonces, public keys) that comfortably fit 255 bytes.
+func (mysqlDialect) TranslateDDL(statement string) string {
+	return mysqlTextPrimaryKey.ReplaceAllString(statement, "VARCHAR(255) PRIMARY KEY")
+}
+
+const mysqlMigrationLockName = "better-auth-migrations"
+
+func (mysqlDialect) AcquireMigrationLock(ctx context.Context, conn *sqlx.DB) (func() error, error) {
+	var acquired int
+	if err := conn.GetContext(ctx, &acquired, "SELECT GET_LOCK(?, -1)", mysqlMigrationLockName); err != nil {
+		return nil, fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	if acquired != 1 {
+		return nil, fmt.Errorf("failed to acquire migration lock")
+	}
+
+	return func() error {
+		_, err := conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", mysqlMigrationLockName)
+		return err
+	}, nil
+}
+
+var _ Dialect = mysqlDialect{}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type postgresDialect struct{}
+
+// Postgres is the Dialect for this codebase's original backend: native array types via
+// pq.Array, `$N` placeholders, and pg_advisory_lock for migration serialization.
+func Postgres() Dialect {
+	return postgresDialect{}
+}
+
+func (postgresDialect) Name() string   { return "postgres" }
+func (postgresDialect) Driver() string { return "postgres" }
+
+func (postgresDialect) ReplacePlaceholders(query string) string {
+	count := 0
+	return regexp.MustCompile(`\?`).ReplaceAllStringFunc(query, func(m string) string {
+		count++
+		return fmt.Sprintf("$%d", count)
+	})
+}
+
+func (postgresDialect) AnyBuilder() AnyBuilder {
+	return postgresAnyBuilder{}
+}
+
+// TranslateDDL is the identity function: the table SQL in pkg/models is already written in
+// Postgres's own dialect.
+func (postgresDialect) TranslateDDL(statement string) string {
+	return statement
+}
+
+// Lock ID: 0x42415554485f4d49 (ASCII for "BAUTH_MI" - Better Auth Migrations)
+const postgresMigrationLockID = 4774929821315686217
+
+func (postgresDialect) AcquireMigrationLock(ctx context.Context, conn *sqlx.DB) (func() error, error) {
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", postgresMigrationLockID); err != nil {
+		return nil, fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+
+	return func() error {
+		_, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", postgresMigrationLockID)
+		return err
+	}, nil
+}
+
+// postgresAnyBuilder renders `column=ANY(?)` backed by pq.Array, Postgres's native array type.
+type postgresAnyBuilder struct{}
+
+func (postgresAnyBuilder) String(column string, values []any) string {
+	return fmt.Sprintf("%s=ANY(?)", column)
+}
+
+func (postgresAnyBuilder) Values(values []any) []any {
+	return []any{pq.Array(values)}
+}
+
+var _ Dialect = postgresDialect{}
+var _ AnyBuilder = postgresAnyBuilder{}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/jmoiron/sqlx"
+
+	_ "modernc.org/sqlite"
+)
+
+type sqliteDialect struct{}
+
+// SQLite is the Dialect for running this codebase's schema against SQLite, making for a
+// dramatically simpler test setup than standing up Postgres or MySQL. Uses modernc.org/sqlite,
+// a pure-Go driver, so it doesn't need cgo. SQLite is single-writer and (in practice here)
+// single-process, so migrations need no session lock.
+func SQLite() Dialect {
+	return sqliteDialect{}
+}
+
+func (sqliteDialect) Name() string   { return "sqlite" }
+func (sqliteDialect) Driver() string { return "sqlite" }
+
+// ReplacePlaceholders is the identity function: SQLite's driver already accepts `?` natively.
+func (sqliteDialect) ReplacePlaceholders(query string) string {
+	return query
+}
+
+func (sqliteDialect) AnyBuilder() AnyBuilder {
+	return expandedAnyBuilder{}
+}
+
+var sqliteBigint = regexp.MustCompile(`(?i)BIGINT`)
+
+// TranslateDDL rewrites BIGINT to INTEGER: SQLite has no true BIGINT storage class, and stores
+// any integer column (up to 8 bytes) as INTEGER regardless of the declared type name.
+func (sqliteDialect) TranslateDDL(statement string) string {
+	return sqliteBigint.ReplaceAllString(statement, "INTEGER")
+}
+
+// AcquireMigrationLock is a no-op: SQLite has no concurrent-writer scenario to guard against
+// here, since this codebase only runs it single-process in tests.
+func (sqliteDialect) AcquireMigrationLock(ctx context.Context, conn *sqlx.DB) (func() error, error) {
+	return func() error { return nil }, nil
+}
+
+var _ Dialect = sqliteDialect{}
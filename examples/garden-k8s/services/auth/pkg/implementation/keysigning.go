@@ -0,0 +1,23 @@
+package implementation
+
+// KeySigningPayload is the body the auth server asks the HSM to sign when registering its
+// access/response public keys: a purpose tag, the public key itself, and the expiration the
+// HSM is being asked to vouch for.
+type KeySigningPayload struct {
+	Purpose    string `json:"purpose"`
+	PublicKey  string `json:"publicKey"`
+	Expiration string `json:"expiration"`
+}
+
+// KeySigningHsm identifies which HSM signing key produced a KeySigningBody's signature.
+type KeySigningHsm struct {
+	Identity     string `json:"identity"`
+	GenerationId string `json:"generationId"`
+}
+
+// KeySigningBody is what the HSM echoes back alongside its signature: the payload it was
+// asked to sign, plus which of its keys signed it.
+type KeySigningBody struct {
+	Payload KeySigningPayload `json:"payload"`
+	Hsm     KeySigningHsm     `json:"hsm"`
+}
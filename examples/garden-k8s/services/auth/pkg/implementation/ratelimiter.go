@@ -0,0 +1,210 @@
+package implementation
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RateLimitContainer configures a lockout policy: MaxAttempts failures within Window trigger
+// a lockout lasting LockoutDuration, during which every call to RateLimiter.Allow for that
+// key is rejected regardless of further failures or successes.
+type RateLimitContainer struct {
+	MaxAttempts     int
+	Window          time.Duration
+	LockoutDuration time.Duration
+}
+
+// RateLimiter throttles attempts keyed by caller-chosen strings (e.g. an identity, a peer IP,
+// or "identity|ip" to require both to be within policy). Callers check Allow before doing the
+// attempt and report the outcome with RecordFailure/RecordSuccess afterward.
+type RateLimiter interface {
+	// Allow reports whether key may attempt now, given MaxAttempts/Window/LockoutDuration.
+	Allow(ctx context.Context, key string) (bool, error)
+	// RecordFailure registers a failed attempt for key, possibly triggering a lockout.
+	RecordFailure(ctx context.Context, key string) error
+	// RecordSuccess clears key's failure history, so a legitimate login isn't penalized by
+	// failures that happened before it.
+	RecordSuccess(ctx context.Context, key string) error
+	// Close releases any resources (e.g. a Redis client) held by the RateLimiter.
+	Close() error
+}
+
+// attemptWindow is one key's in-progress failure count and, once triggered, lockout expiry.
+type attemptWindow struct {
+	failures    int
+	windowStart time.Time
+	lockedUntil time.Time
+}
+
+// InMemoryRateLimiter is a single-process RateLimiter, suitable for the lite (in-memory)
+// example or tests. It does not survive process restarts and does not coordinate across
+// replicas — use RedisRateLimiter for that.
+type InMemoryRateLimiter struct {
+	policy RateLimitContainer
+
+	mu      sync.Mutex
+	windows map[string]*attemptWindow
+}
+
+func NewInMemoryRateLimiter(policy RateLimitContainer) *InMemoryRateLimiter {
+	return &InMemoryRateLimiter{
+		policy:  policy,
+		windows: map[string]*attemptWindow{},
+	}
+}
+
+func (l *InMemoryRateLimiter) Allow(ctx context.Context, key string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	window, ok := l.windows[key]
+	if !ok {
+		return true, nil
+	}
+
+	now := time.Now()
+	if now.Before(window.lockedUntil) {
+		return false, nil
+	}
+
+	if now.Sub(window.windowStart) > l.policy.Window {
+		delete(l.windows, key)
+	}
+
+	return true, nil
+}
+
+func (l *InMemoryRateLimiter) RecordFailure(ctx context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+
+	window, ok := l.windows[key]
+	if !ok || now.Sub(window.windowStart) > l.policy.Window {
+		window = &attemptWindow{windowStart: now}
+		l.windows[key] = window
+	}
+
+	window.failures++
+	if window.failures >= l.policy.MaxAttempts {
+		window.lockedUntil = now.Add(l.policy.LockoutDuration)
+	}
+
+	return nil
+}
+
+func (l *InMemoryRateLimiter) RecordSuccess(ctx context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.windows, key)
+
+	return nil
+}
+
+// Close is a no-op: InMemoryRateLimiter holds no external resources.
+func (l *InMemoryRateLimiter) Close() error {
+	return nil
+}
+
+// RedisRateLimiter is the distributed RateLimiter, so a lockout triggered against one replica
+// is honored by every other replica — required for the k8s example, which runs several.
+type RedisRateLimiter struct {
+	policy RateLimitContainer
+	client *redis.Client
+}
+
+func NewRedisRateLimiter(policy RateLimitContainer) (*RedisRateLimiter, error) {
+	redisHost := os.Getenv("REDIS_HOST")
+	if redisHost == "" {
+		redisHost = "redis:6379"
+	}
+
+	redisDbRateLimitString := os.Getenv("REDIS_DB_RATE_LIMIT")
+	redisDbRateLimit, err := strconv.Atoi(redisDbRateLimitString)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RedisRateLimiter{
+		policy: policy,
+		client: redis.NewClient(&redis.Options{
+			Addr: redisHost,
+			DB:   redisDbRateLimit,
+		}),
+	}, nil
+}
+
+func lockoutKey(key string) string {
+	return "ratelimit:lockout:" + key
+}
+
+func failuresKey(key string) string {
+	return "ratelimit:failures:" + key
+}
+
+func (l *RedisRateLimiter) Allow(ctx context.Context, key string) (bool, error) {
+	locked, err := RetryRedisOperation(ctx, func() (int64, error) {
+		return l.client.Exists(ctx, lockoutKey(key)).Result()
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return locked == 0, nil
+}
+
+func (l *RedisRateLimiter) RecordFailure(ctx context.Context, key string) error {
+	failures, err := RetryRedisOperation(ctx, func() (int64, error) {
+		pipe := l.client.TxPipeline()
+		incr := pipe.Incr(ctx, failuresKey(key))
+		pipe.Expire(ctx, failuresKey(key), l.policy.Window)
+		_, err := pipe.Exec(ctx)
+		if err != nil {
+			return 0, err
+		}
+
+		return incr.Val(), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if failures >= int64(l.policy.MaxAttempts) {
+		_, err = RetryRedisOperation(ctx, func() (struct{}, error) {
+			return struct{}{}, l.client.Set(ctx, lockoutKey(key), true, l.policy.LockoutDuration).Err()
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (l *RedisRateLimiter) RecordSuccess(ctx context.Context, key string) error {
+	_, err := RetryRedisOperation(ctx, func() (struct{}, error) {
+		return struct{}{}, l.client.Del(ctx, failuresKey(key)).Err()
+	})
+
+	return err
+}
+
+// Close releases the underlying Redis client.
+func (l *RedisRateLimiter) Close() error {
+	return l.client.Close()
+}
+
+var _ RateLimiter = (*InMemoryRateLimiter)(nil)
+var _ RateLimiter = (*RedisRateLimiter)(nil)
+
+// ErrRateLimited is returned by handlers (not RateLimiter itself) when Allow reports a key is
+// currently locked out, so callers can distinguish it from other failures.
+var ErrRateLimited = fmt.Errorf("rate limited")
@@ -6,9 +6,9 @@ import (
 	"time"
 )
 
-// retryRedisOperation executes a Redis operation with retry logic and exponential backoff.
+// RetryRedisOperation executes a Redis operation with retry logic and exponential backoff.
 // This ensures graceful recovery when Redis restarts or connections drop.
-func retryRedisOperation[T any](ctx context.Context, operation func() (T, error)) (T, error) {
+func RetryRedisOperation[T any](ctx context.Context, operation func() (T, error)) (T, error) {
 	const maxRetries = 3
 	const initialBackoff = 100 * time.Millisecond
 
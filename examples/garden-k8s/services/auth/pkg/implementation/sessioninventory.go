@@ -0,0 +1,231 @@
+package implementation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// SessionInfo is what SessionInventory remembers about one active session, enough for an
+// operator-facing "which devices am I logged into" listing and for RevokeSession to target a
+// specific one.
+type SessionInfo struct {
+	SessionId string    `json:"sessionId"`
+	Identity  string    `json:"identity"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// SessionInventory tracks which sessions are currently active per identity, so a deployment
+// can let operators list and individually revoke their own sessions ("kick out other
+// devices"), or force-revoke every prior session for an identity when EnableMultiLogin is
+// false and a new login should replace rather than accumulate.
+type SessionInventory interface {
+	// Record registers a newly created session, active until info.ExpiresAt.
+	Record(ctx context.Context, info SessionInfo) error
+	// List returns every currently active (not yet revoked or expired) session for identity.
+	List(ctx context.Context, identity string) ([]SessionInfo, error)
+	// Revoke ends sessionId immediately, regardless of which identity it belongs to.
+	Revoke(ctx context.Context, sessionId string) error
+	// RevokeAll ends every currently active session for identity.
+	RevokeAll(ctx context.Context, identity string) error
+	// Close releases any resources (e.g. a Redis client) held by the SessionInventory.
+	Close() error
+}
+
+// RedisSessionInventory is the default SessionInventory: one Redis SET per identity holding
+// its active session ids, and one Redis string per session id holding its SessionInfo JSON —
+// the same "set membership + per-entry TTL" shape KeyHashReservationStore and RateLimiter use
+// elsewhere in this codebase.
+type RedisSessionInventory struct {
+	client *redis.Client
+}
+
+func NewRedisSessionInventory() (*RedisSessionInventory, error) {
+	redisHost := os.Getenv("REDIS_HOST")
+	if redisHost == "" {
+		redisHost = "redis:6379"
+	}
+
+	redisDbSessionInventoryString := os.Getenv("REDIS_DB_SESSION_INVENTORY")
+	redisDbSessionInventory, err := strconv.Atoi(redisDbSessionInventoryString)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RedisSessionInventory{
+		client: redis.NewClient(&redis.Options{
+			Addr: redisHost,
+			DB:   redisDbSessionInventory,
+		}),
+	}, nil
+}
+
+func identitySessionsKey(identity string) string {
+	return "sessions:identity:" + identity
+}
+
+func sessionKey(sessionId string) string {
+	return "sessions:session:" + sessionId
+}
+
+func (s *RedisSessionInventory) Record(ctx context.Context, info SessionInfo) error {
+	infoJSON, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session info: %w", err)
+	}
+
+	ttl := time.Until(info.ExpiresAt)
+	if ttl <= 0 {
+		return fmt.Errorf("session %q already expired", info.SessionId)
+	}
+
+	// With multi-login, the identity's session set can already hold a longer-lived session
+	// than this one; overwriting its TTL with ours would let that other session silently
+	// disappear from List/RevokeAll before it actually expires. Extend the set's TTL rather
+	// than resetting it.
+	existingTTL, err := RetryRedisOperation(ctx, func() (time.Duration, error) {
+		return s.client.PTTL(ctx, identitySessionsKey(info.Identity)).Result()
+	})
+	if err != nil {
+		return err
+	}
+
+	setTTL := ttl
+	if existingTTL > setTTL {
+		setTTL = existingTTL
+	}
+
+	_, err = RetryRedisOperation(ctx, func() (struct{}, error) {
+		pipe := s.client.TxPipeline()
+		pipe.Set(ctx, sessionKey(info.SessionId), infoJSON, ttl)
+		pipe.SAdd(ctx, identitySessionsKey(info.Identity), info.SessionId)
+		pipe.Expire(ctx, identitySessionsKey(info.Identity), setTTL)
+		_, err := pipe.Exec(ctx)
+		return struct{}{}, err
+	})
+
+	return err
+}
+
+func (s *RedisSessionInventory) List(ctx context.Context, identity string) ([]SessionInfo, error) {
+	sessionIds, err := RetryRedisOperation(ctx, func() ([]string, error) {
+		return s.client.SMembers(ctx, identitySessionsKey(identity)).Result()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sessionIds) == 0 {
+		return []SessionInfo{}, nil
+	}
+
+	sessionKeys := make([]string, len(sessionIds))
+	for i, sessionId := range sessionIds {
+		sessionKeys[i] = sessionKey(sessionId)
+	}
+
+	values, err := RetryRedisOperation(ctx, func() ([]any, error) {
+		return s.client.MGet(ctx, sessionKeys...).Result()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]SessionInfo, 0, len(values))
+	staleSessionIds := make([]string, 0)
+	for i, value := range values {
+		if value == nil {
+			// Expired out of Redis without going through Revoke (TTL ran out naturally) —
+			// prune it from the identity's set lazily rather than on every tick.
+			staleSessionIds = append(staleSessionIds, sessionIds[i])
+			continue
+		}
+
+		infoString, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type for session info")
+		}
+
+		var info SessionInfo
+		if err := json.Unmarshal([]byte(infoString), &info); err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, info)
+	}
+
+	if len(staleSessionIds) > 0 {
+		_, _ = RetryRedisOperation(ctx, func() (struct{}, error) {
+			return struct{}{}, s.client.SRem(ctx, identitySessionsKey(identity), toAnySlice(staleSessionIds)...).Err()
+		})
+	}
+
+	return sessions, nil
+}
+
+func (s *RedisSessionInventory) Revoke(ctx context.Context, sessionId string) error {
+	infoString, err := RetryRedisOperation(ctx, func() (string, error) {
+		return s.client.Get(ctx, sessionKey(sessionId)).Result()
+	})
+	if err == redis.Nil {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var info SessionInfo
+	if err := json.Unmarshal([]byte(infoString), &info); err != nil {
+		return err
+	}
+
+	_, err = RetryRedisOperation(ctx, func() (struct{}, error) {
+		pipe := s.client.TxPipeline()
+		pipe.Del(ctx, sessionKey(sessionId))
+		pipe.SRem(ctx, identitySessionsKey(info.Identity), sessionId)
+		_, err := pipe.Exec(ctx)
+		return struct{}{}, err
+	})
+
+	return err
+}
+
+func (s *RedisSessionInventory) RevokeAll(ctx context.Context, identity string) error {
+	sessionIds, err := RetryRedisOperation(ctx, func() ([]string, error) {
+		return s.client.SMembers(ctx, identitySessionsKey(identity)).Result()
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, sessionId := range sessionIds {
+		if err := s.Revoke(ctx, sessionId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close releases the underlying Redis client.
+func (s *RedisSessionInventory) Close() error {
+	return s.client.Close()
+}
+
+// toAnySlice adapts a []string to the []any SRem's variadic signature expects.
+func toAnySlice(strings []string) []any {
+	values := make([]any, len(strings))
+	for i, s := range strings {
+		values[i] = s
+	}
+
+	return values
+}
+
+var _ SessionInventory = (*RedisSessionInventory)(nil)